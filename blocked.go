@@ -0,0 +1,143 @@
+package gatosat
+
+const (
+	//blockedOccurrenceLimit is the maximum number of the clauses of a literal tried by the blocked and covered clause elimination
+	blockedOccurrenceLimit = 200
+	//coveredClauseLimit is the maximum size of a clause extended by the covered literal addition
+	coveredClauseLimit = 100
+)
+
+//eliminateBlocked runs the blocked clause elimination and the covered clause elimination on the problem clauses once before the first search
+//A clause C is blocked on its literal l if every resolvent of C on l with the other clauses is a tautology.
+//The covered clause elimination extends C by the literals in all the non-tautological resolvents on l (covered literal addition)
+//until the extended clause is blocked or a tautology.
+//The removed clauses are kept in the elimination stack with the literals which the extension of the model makes true
+//It returns false if the problem is unsatisfiable
+func (s *Solver) eliminateBlocked() bool {
+	if !s.opts.BlockedClauseElimination && !s.opts.CoveredClauseElimination || s.blockedDone || len(s.scopes) > 0 || s.propagator != nil {
+		return s.ok
+	}
+	s.blockedDone = true
+	if !s.simplify() {
+		return false
+	}
+	occ := make([][]ClauseReference, 2*s.NumVars())
+	for _, cr := range s.clauses {
+		s.addOccurrences(occ, cr)
+	}
+	removable := s.removableVars()
+	marks := make([]bool, 2*s.NumVars())
+	for _, cr := range s.clauses {
+		if s.claAllocator.Clauses[cr].IsRemoved() {
+			continue
+		}
+		c := s.claAllocator.GetClause(cr)
+		steps := s.coverClause(cr, c, occ, removable, marks)
+		if steps == nil {
+			continue
+		}
+		//Only the first entry is the removed clause, which stays in the proof, so it can be added back with the same ID
+		steps[0].id, steps[0].removed = c.id, true
+		for _, e := range steps {
+			s.witness[e.witness.Var()] = true
+		}
+		s.elimStack = append(s.elimStack, steps...)
+		s.deleteClause(cr)
+		if len(steps) == 1 {
+			s.statistics.BlockedClauseCount++
+		} else {
+			s.statistics.CoveredClauseCount++
+		}
+	}
+	s.clauses = s.liveClauses(s.clauses)
+	return true
+}
+
+//coverClause returns the entries of the elimination stack removing the clause if it is blocked or covered
+//The first entry is the clause and each of the others is the clause extended by the covered literals of the witness of the previous one.
+//It returns nil if the clause can't be removed
+func (s *Solver) coverClause(cr ClauseReference, c *Clause, occ [][]ClauseReference, removable, marks []bool) []elimEntry {
+	lits := append([]Lit(nil), c.Data[:c.Size()]...)
+	for _, q := range lits {
+		marks[q.X] = true
+	}
+	defer func() {
+		for _, q := range lits {
+			marks[q.X] = false
+		}
+	}()
+
+	var steps []elimEntry
+	for changed := true; changed && len(lits) <= coveredClauseLimit; {
+		changed = false
+		for i := 0; i < len(lits); i++ {
+			l := lits[i]
+			if !removable[l.Var()] || len(occ[l.Flip().X]) > blockedOccurrenceLimit {
+				continue
+			}
+			blocked := true
+			var covered []Lit
+			for _, dr := range occ[l.Flip().X] {
+				if dr == cr || s.claAllocator.Clauses[dr].IsRemoved() {
+					continue
+				}
+				d := s.claAllocator.GetClause(dr)
+				if resolventTautology(d, l, marks) {
+					continue
+				}
+				if blocked {
+					blocked = false
+					covered = coveredLits(d, l, marks, nil)
+				} else {
+					covered = coveredLits(d, l, marks, covered)
+				}
+				if !s.opts.CoveredClauseElimination || len(covered) == 0 {
+					break
+				}
+			}
+			step := elimEntry{witness: l, lits: append([]Lit(nil), lits...)}
+			if blocked {
+				return append(steps, step)
+			}
+			if !s.opts.CoveredClauseElimination || len(covered) == 0 {
+				continue
+			}
+			steps = append(steps, step)
+			for _, q := range covered {
+				if marks[q.Flip().X] {
+					//The extended clause is a tautology
+					return steps
+				}
+			}
+			for _, q := range covered {
+				marks[q.X] = true
+				lits = append(lits, q)
+			}
+			changed = true
+		}
+	}
+	return nil
+}
+
+//resolventTautology returns true if the resolvent of the marked clause and d on l is a tautology
+func resolventTautology(d *Clause, l Lit, marks []bool) bool {
+	for i := 0; i < d.Size(); i++ {
+		if q := d.At(i); q != l.Flip() && marks[q.Flip().X] {
+			return true
+		}
+	}
+	return false
+}
+
+//coveredLits returns the literals of d other than ~l which are not marked, restricted to the literals of covered if it is not nil
+func coveredLits(d *Clause, l Lit, marks []bool, covered []Lit) []Lit {
+	var lits []Lit
+	for i := 0; i < d.Size(); i++ {
+		q := d.At(i)
+		if q == l.Flip() || marks[q.X] || covered != nil && !containsLit(covered, q) {
+			continue
+		}
+		lits = append(lits, q)
+	}
+	return lits
+}
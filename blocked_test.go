@@ -0,0 +1,88 @@
+package gatosat
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/togatoga/gatosat/lrat"
+)
+
+func TestEliminateBlocked(t *testing.T) {
+	rnd := rand.New(rand.NewSource(6))
+	numVars := 14
+	var blocked, covered uint64
+	for i := 0; i < 400; i++ {
+		clauses := randomCircuit(rnd, 6, 8, rnd.Intn(4))
+		opts := DefaultOptions()
+		opts.Elimination = i%4 == 0
+		opts.BlockedClauseElimination = true
+		opts.CoveredClauseElimination = i%2 == 0
+		s, err := NewSolverWithOptions(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var proof bytes.Buffer
+		s.SetClauseStore(NewMemoryClauseStore())
+		s.SetProof(&proof, ProofLRAT)
+		for v := 0; v < numVars; v++ {
+			s.NewVar()
+		}
+		for _, c := range clauses {
+			s.AddClause(append([]Lit(nil), c...))
+		}
+		status := s.Solve()
+		if expected := bruteForce(numVars, clauses, nil); status != expected {
+			t.Fatalf("The solver returns a wrong value: %v (expected %v) clauses %v", status, expected, clauses)
+		}
+		if status == LitBoolTrue {
+			if err := s.VerifyModel(); err != nil {
+				t.Fatalf("The extended model is wrong: %v", err)
+			}
+		} else {
+			s.FlushProof()
+			if err := lrat.NewChecker(dimacsClauses(clauses)).Check(&proof); err != nil {
+				t.Fatalf("The LRAT proof is not verified: %v", err)
+			}
+		}
+		if err := s.CheckInvariants(); err != nil {
+			t.Fatal(err)
+		}
+		blocked += s.Statistics().BlockedClauseCount
+		covered += s.Statistics().CoveredClauseCount
+		//Each blocked or covered clause is added back once by the restoration
+		removed := uint64(0)
+		for _, e := range s.elimStack {
+			if e.removed && !s.eliminated[e.witness.Var()] {
+				removed++
+			}
+		}
+		if removed != s.Statistics().BlockedClauseCount+s.Statistics().CoveredClauseCount {
+			t.Fatalf("The removed clauses are not in the elimination stack once: %d %d", removed, s.Statistics().BlockedClauseCount+s.Statistics().CoveredClauseCount)
+		}
+		if status != LitBoolTrue {
+			continue
+		}
+
+		//The enumeration restores the removed clauses, so the cubes are the implicants of the problem
+		if i%8 == 0 {
+			checkCubes(t, s.Clone(), numVars, clauses)
+		}
+
+		//The clauses on the witness variables bring the removed clauses back
+		c := []Lit{*NewLit(Var(rnd.Intn(numVars)), rnd.Intn(2) == 0), *NewLit(Var(rnd.Intn(numVars)), rnd.Intn(2) == 0)}
+		clauses = append(clauses, c)
+		s.AddClause(append([]Lit(nil), c...))
+		assumptions := []Lit{*NewLit(Var(rnd.Intn(numVars)), rnd.Intn(2) == 0)}
+		status = s.SolveWithAssumptions(assumptions)
+		if expected := bruteForce(numVars, clauses, assumptions); status != expected {
+			t.Fatalf("The solver returns a wrong value after adding a clause: %v (expected %v)", status, expected)
+		}
+		if status == LitBoolTrue {
+			checkModel(t, s.Model(), clauses, assumptions)
+		}
+	}
+	if blocked == 0 || covered == 0 {
+		t.Fatalf("No clause is removed: %d blocked %d covered", blocked, covered)
+	}
+}
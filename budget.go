@@ -0,0 +1,88 @@
+package gatosat
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+//SetConflictBudget limits the number of conflicts of the following calls to Solve
+//The search returns LitBoolUndef when the solver runs out of the budget
+func (s *Solver) SetConflictBudget(x int64) {
+	s.conflictBudget = int64(s.statistics.ConflictCount) + x
+}
+
+//SetPropagationBudget limits the number of propagations of the following calls to Solve
+//The search returns LitBoolUndef when the solver runs out of the budget
+func (s *Solver) SetPropagationBudget(x int64) {
+	s.propagationBudget = int64(s.statistics.PropagationCount) + x
+}
+
+//BudgetOff removes the conflict and propagation budgets
+func (s *Solver) BudgetOff() {
+	s.conflictBudget = -1
+	s.propagationBudget = -1
+}
+
+//Interrupt stops the running search as soon as possible
+//It is safe to call Interrupt from another goroutine
+//The interrupt is cleared when Solve returns
+func (s *Solver) Interrupt() {
+	atomic.StoreInt32(&s.asyncInterrupt, 1)
+}
+
+func (s *Solver) clearInterrupt() {
+	atomic.StoreInt32(&s.asyncInterrupt, 0)
+}
+
+//watchContext interrupts the search when ctx is done
+//The returned function stops watching and clears the interrupt
+func (s *Solver) watchContext(ctx context.Context) func() {
+	if ctx.Done() == nil {
+		return s.clearInterrupt
+	}
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		select {
+		case <-ctx.Done():
+			s.Interrupt()
+		case <-done:
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+		s.clearInterrupt()
+	}
+}
+
+//SetTerminate registers a function polled during the search
+//The search stops and returns LitBoolUndef when it returns true. nil removes the function
+func (s *Solver) SetTerminate(terminate func() bool) {
+	s.terminate = terminate
+}
+
+//SetLearn registers a function called with every learnt clause whose size is at most maxLength
+//lbd is the number of distinct decision levels in the clause (literal block distance)
+//The clause is a copy, so the function may modify or retain it. nil removes the function
+func (s *Solver) SetLearn(maxLength int, learn func(lits []Lit, lbd int)) {
+	s.learn = learn
+	s.learnMaxLength = maxLength
+}
+
+//SetImport registers a function called at every restart to inject clauses into the search
+//The returned clauses are added as learnt clauses, so they must be implied by the clauses of the solver
+//(e.g. clauses learnt by another solver for the same problem). nil removes the function
+func (s *Solver) SetImport(importClauses func() [][]Lit) {
+	s.importClauses = importClauses
+}
+
+func (s *Solver) withinBudget() bool {
+	if s.terminate != nil && s.terminate() {
+		return false
+	}
+	return atomic.LoadInt32(&s.asyncInterrupt) == 0 &&
+		(s.conflictBudget < 0 || int64(s.statistics.ConflictCount) < s.conflictBudget) &&
+		(s.propagationBudget < 0 || int64(s.statistics.PropagationCount) < s.propagationBudget)
+}
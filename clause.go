@@ -1,4 +1,4 @@
-package main
+package gatosat
 
 import (
 	"fmt"
@@ -22,6 +22,7 @@ type Clause struct {
 	header Header  // The header represents
 	Data   []Lit   // The Data is the list of the literal
 	Act    float32 // The Act is the clause activity. when we need to delete clauses, we use it
+	id     uint64  // The id is the ID of the clause in the proof
 }
 
 //NewClause returns a pointer of a new clause
@@ -40,6 +41,20 @@ func NewClause(ps []Lit, useExtra, learnt bool) *Clause {
 	return &c
 }
 
+//Clone returns a deep copy of the clause
+func (c *Clause) Clone() *Clause {
+	clone := *c
+	clone.Data = make([]Lit, len(c.Data))
+	copy(clone.Data, c.Data)
+	return &clone
+}
+
+//ID returns the ID of the clause
+//The clauses added by AddClause are numbered from 1 in the order they are added and the derived clauses follow them
+func (c *Clause) ID() uint64 {
+	return c.id
+}
+
 func (c *Clause) Size() int {
 	return c.header.Size
 }
@@ -88,21 +103,29 @@ func (s *Solver) removeSatisfied(data *[]ClauseReference) {
 	copiedIdx := 0
 
 	for lastIdx := 0; lastIdx < len(*data); lastIdx++ {
-		c := s.ClaAllocator.GetClause((*data)[lastIdx])
+		c := s.claAllocator.GetClause((*data)[lastIdx])
 		if s.satisfied(c) {
 			s.removeClause((*data)[lastIdx])
 		} else {
 			//Trim Clause
-			if !(s.ValueLit(c.At(0)) == LitBoolUndef && s.ValueLit(c.At(1)) == LitBoolUndef) {
-				panic(fmt.Errorf("The 0th and 1th of clause value is not LitBoolUndef: v1: %d = %d v2: %d = %d", c.At(0), s.ValueLit(c.At(0)), c.At(1), s.ValueLit(c.At(1))))
+			if !(s.valueLit(c.At(0)) == LitBoolUndef && s.valueLit(c.At(1)) == LitBoolUndef) {
+				panic(fmt.Errorf("The 0th and 1th of clause value is not LitBoolUndef: v1: %d = %d v2: %d = %d", c.At(0), s.valueLit(c.At(0)), c.At(1), s.valueLit(c.At(1))))
+			}
+			var original []Lit
+			if s.proof != nil {
+				original = append(original, c.Data[:c.Size()]...)
 			}
+			//The false literals are moved behind the size, so Restore can bring the clause back
 			for k := 2; k < c.Size(); k++ {
-				if s.ValueLit(c.At(k)) == LitBoolFalse {
-					c.Data[k] = c.Last()
+				if s.valueLit(c.At(k)) == LitBoolFalse {
+					c.Data[k], c.Data[c.Size()-1] = c.Last(), c.Data[k]
 					k--
 					c.Pop()
 				}
 			}
+			if s.proof != nil && c.Size() < len(original) {
+				c.id = s.proofTrimmed(c.id, c.Data[:c.Size()], original)
+			}
 			(*data)[copiedIdx] = (*data)[lastIdx]
 			copiedIdx++
 		}
@@ -111,24 +134,24 @@ func (s *Solver) removeSatisfied(data *[]ClauseReference) {
 }
 
 func (s *Solver) detachClause(cr ClauseReference) {
-	c := s.ClaAllocator.GetClause(cr)
+	c := s.claAllocator.GetClause(cr)
 	if c.Size() <= 1 {
 		panic(fmt.Errorf("The size of clause is less than 2: %d", c.Size()))
 	}
 	firstLit := c.At(0)
 	secondLit := c.At(1)
-	RemoveWatcher(s.Watches, firstLit.Flip(), NewWatcher(cr, secondLit))
-	RemoveWatcher(s.Watches, secondLit.Flip(), NewWatcher(cr, firstLit))
+	RemoveWatcher(s.watches, firstLit.Flip(), NewWatcher(cr, secondLit))
+	RemoveWatcher(s.watches, secondLit.Flip(), NewWatcher(cr, firstLit))
 	if c.Learnt() {
-		s.Statistics.NumLearnts--
+		s.statistics.NumLearnts--
 	} else {
-		s.Statistics.NumClauses--
+		s.statistics.NumClauses--
 	}
 }
 
 func (s *Solver) locked(c *Clause) bool {
 	firstLit := c.At(0)
-	if s.ValueLit(firstLit) == LitBoolTrue && s.Reason(firstLit.Var()) != ClaRefUndef {
+	if reason := s.varData[firstLit.Var()].Reason; s.valueLit(firstLit) == LitBoolTrue && reason != ClaRefUndef && reason != claRefLazy {
 		return true
 	}
 	return false
@@ -136,7 +159,7 @@ func (s *Solver) locked(c *Clause) bool {
 
 func (s *Solver) satisfied(c *Clause) bool {
 	for i := 0; i < c.Size(); i++ {
-		if s.ValueLit(c.At(i)) == LitBoolTrue {
+		if s.valueLit(c.At(i)) == LitBoolTrue {
 			return true
 		}
 	}
@@ -144,18 +167,27 @@ func (s *Solver) satisfied(c *Clause) bool {
 }
 
 func (s *Solver) removeClause(cr ClauseReference) {
-	c := s.ClaAllocator.GetClause(cr)
-	s.detachClause(cr)
+	c := s.claAllocator.GetClause(cr)
+	if s.proof != nil {
+		s.proof.delete(c.id, c.Data[:c.Size()])
+	}
+	s.deleteClause(cr)
+}
+
+//deleteClause removes the clause without writing its deletion into the proof
+func (s *Solver) deleteClause(cr ClauseReference) {
+	c := s.claAllocator.GetClause(cr)
 	firstLit := c.At(0)
+	s.detachClause(cr)
 	if s.locked(c) {
-		s.VarData[firstLit.Var()].Reason = ClaRefUndef
+		s.varData[firstLit.Var()].Reason = ClaRefUndef
 	}
 	c.SetMark(DeletedMark)
-	s.ClaAllocator.FreeClause(cr)
+	s.claAllocator.FreeClause(cr)
 }
 
 func (s *Solver) attachClause(claRef ClauseReference) (err error) {
-	clause := s.ClaAllocator.GetClause(claRef)
+	clause := s.claAllocator.GetClause(claRef)
 
 	if clause.Size() < 2 {
 		return fmt.Errorf("The size of clause is less than 2 %v", clause)
@@ -163,13 +195,13 @@ func (s *Solver) attachClause(claRef ClauseReference) (err error) {
 
 	firstLit := clause.At(0)
 	secondLit := clause.At(1)
-	s.Watches.Append(firstLit.Flip(), NewWatcher(claRef, secondLit))
-	s.Watches.Append(secondLit.Flip(), NewWatcher(claRef, firstLit))
+	s.watches.Append(firstLit.Flip(), NewWatcher(claRef, secondLit))
+	s.watches.Append(secondLit.Flip(), NewWatcher(claRef, firstLit))
 
 	if clause.Learnt() {
-		s.Statistics.NumLearnts++
+		s.statistics.NumLearnts++
 	} else {
-		s.Statistics.NumClauses++
+		s.statistics.NumClauses++
 	}
 	return nil
 }
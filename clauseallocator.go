@@ -1,4 +1,4 @@
-package main
+package gatosat
 
 import (
 	"fmt"
@@ -27,7 +27,7 @@ func NewClauseAllocator() *ClauseAllocator {
 //NewAllocate allocates a new clause and returns a reference for a clause
 func (c *ClauseAllocator) NewAllocate(lits []Lit, learnt bool) (ClauseReference, error) {
 	cref := c.Qhead
-	if cref >= ClaRefUndef {
+	if cref >= claRefLazy {
 		panic(fmt.Errorf("The overflow for a clause allocator happnes"))
 	}
 	c.Clauses = append(c.Clauses, NewClause(lits, false, learnt))
@@ -35,6 +35,16 @@ func (c *ClauseAllocator) NewAllocate(lits []Lit, learnt bool) (ClauseReference,
 	return cref, nil
 }
 
+//Clone returns a deep copy of the allocator
+//The references of the clauses are the same in the copy
+func (c *ClauseAllocator) Clone() *ClauseAllocator {
+	clone := &ClauseAllocator{Qhead: c.Qhead, Clauses: make([]*Clause, len(c.Clauses)), WastedSize: c.WastedSize}
+	for i, cla := range c.Clauses {
+		clone.Clauses[i] = cla.Clone()
+	}
+	return clone
+}
+
 //GetClause returns a pointer for a clause
 //check whether the reference is invalid or not
 func (c *ClauseAllocator) GetClause(claRef ClauseReference) (clause *Clause) {
@@ -1,4 +1,4 @@
-package main
+package gatosat
 
 import (
 	"math/rand"
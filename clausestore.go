@@ -0,0 +1,99 @@
+package gatosat
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+//ClauseStore keeps a copy of the clauses added to the solver
+//The solver simplifies its clauses destructively, so the copy is used to verify the models
+type ClauseStore interface {
+	//Append adds a copy of the clause
+	Append(lits []Lit) error
+	//ForEach calls fn with every clause in the order they are added until fn returns false
+	//The clause must not be modified or retained by fn
+	ForEach(fn func(lits []Lit) bool) error
+}
+
+//MemoryClauseStore keeps the clauses in memory
+type MemoryClauseStore struct {
+	clauses [][]Lit
+}
+
+//NewMemoryClauseStore returns an empty MemoryClauseStore
+func NewMemoryClauseStore() *MemoryClauseStore {
+	return &MemoryClauseStore{}
+}
+
+//Append adds a copy of the clause
+func (m *MemoryClauseStore) Append(lits []Lit) error {
+	m.clauses = append(m.clauses, append([]Lit(nil), lits...))
+	return nil
+}
+
+//ForEach calls fn with every clause in the order they are added until fn returns false
+func (m *MemoryClauseStore) ForEach(fn func(lits []Lit) bool) error {
+	for _, lits := range m.clauses {
+		if !fn(lits) {
+			break
+		}
+	}
+	return nil
+}
+
+//FileClauseStore keeps the clauses in a temporary file for huge problems
+//The clauses are written in the encoding of binary DRAT
+type FileClauseStore struct {
+	file *os.File
+	w    *bufio.Writer
+	buf  []byte
+}
+
+//NewFileClauseStore returns a FileClauseStore whose file is created in dir
+//The default directory for temporary files is used if dir is empty
+func NewFileClauseStore(dir string) (*FileClauseStore, error) {
+	file, err := ioutil.TempFile(dir, "gatosat-clauses-")
+	if err != nil {
+		return nil, err
+	}
+	return &FileClauseStore{file: file, w: bufio.NewWriter(file)}, nil
+}
+
+//Append adds a copy of the clause
+func (f *FileClauseStore) Append(lits []Lit) error {
+	f.buf = appendBinaryLits(f.buf[:0], lits)
+	_, err := f.w.Write(f.buf)
+	return err
+}
+
+//ForEach calls fn with every clause in the order they are added until fn returns false
+func (f *FileClauseStore) ForEach(fn func(lits []Lit) bool) error {
+	if err := f.w.Flush(); err != nil {
+		return err
+	}
+	size, err := f.file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	in := bufio.NewReader(io.NewSectionReader(f.file, 0, size))
+	for {
+		if _, err := in.Peek(1); err == io.EOF {
+			return nil
+		}
+		lits, err := readBinaryLits(in)
+		if err != nil {
+			return err
+		}
+		if !fn(lits) {
+			return nil
+		}
+	}
+}
+
+//Close removes the temporary file
+func (f *FileClauseStore) Close() error {
+	f.file.Close()
+	return os.Remove(f.file.Name())
+}
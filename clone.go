@@ -0,0 +1,199 @@
+package gatosat
+
+import "fmt"
+
+//Clone returns a deep copy of the solver
+//The copy shares no state with the solver, so both can be used concurrently
+//The functions registered by SetTerminate, SetLearn and SetImport, the external propagator, the proof writer
+//and the clause store are not copied
+func (s *Solver) Clone() *Solver {
+	if s.decisionLevel() != 0 {
+		panic(fmt.Errorf("The decision level is not zero: %d", s.decisionLevel()))
+	}
+	if s.propagator != nil {
+		s.materializeLazyReasons()
+	}
+	statistics := *s.statistics
+	return &Solver{
+		opts:                        s.opts,
+		randomSeed:                  s.randomSeed,
+		claAllocator:                s.claAllocator.Clone(),
+		clauses:                     append([]ClauseReference(nil), s.clauses...),
+		learnts:                     append([]ClauseReference(nil), s.learnts...),
+		watches:                     s.watches.Clone(),
+		assigns:                     append([]LitBool(nil), s.assigns...),
+		polarity:                    append([]LitBool(nil), s.polarity...),
+		userPolarity:                append([]LitBool(nil), s.userPolarity...),
+		qhead:                       s.qhead,
+		trail:                       append([]Lit(nil), s.trail...),
+		trailLim:                    append([]int(nil), s.trailLim...),
+		nextVar:                     s.nextVar,
+		decision:                    append([]bool(nil), s.decision...),
+		varData:                     append([]VarData(nil), s.varData...),
+		varOrder:                    s.varOrder.Clone(),
+		ok:                          s.ok,
+		varIncreaseRatio:            s.varIncreaseRatio,
+		clauseActivityIncreaseRatio: s.clauseActivityIncreaseRatio,
+		maxNumLearnt:                s.maxNumLearnt,
+		learntSizeAdjustConflict:    s.learntSizeAdjustConflict,
+		seen:                        append([]bool(nil), s.seen...),
+		model:                       append([]LitBool(nil), s.model...),
+		assumptions:                 append([]Lit(nil), s.assumptions...),
+		conflict:                    append([]Lit(nil), s.conflict...),
+		scopes:                      append([]Lit(nil), s.scopes...),
+		activation:                  append([]bool(nil), s.activation...),
+		observed:                    make([]bool, len(s.observed)),
+		conflictBudget:              s.conflictBudget,
+		propagationBudget:           s.propagationBudget,
+		clauseIDCount:               s.clauseIDCount,
+		unitID:                      append([]uint64(nil), s.unitID...),
+		frozen:                      append([]bool(nil), s.frozen...),
+		eliminated:                  append([]bool(nil), s.eliminated...),
+		elimStack:                   append([]elimEntry(nil), s.elimStack...),
+		elimDone:                    s.elimDone,
+		witness:                     append([]bool(nil), s.witness...),
+		blockedDone:                 s.blockedDone,
+		nextSubsumption:             s.nextSubsumption,
+		probeNext:                   s.probeNext,
+		nextProbe:                   s.nextProbe,
+		probeSearchPropagations:     s.probeSearchPropagations,
+		statistics:                  &statistics,
+	}
+}
+
+//Snapshot is the record of the state of a solver at decision level 0 which Restore rolls back to
+//The clauses are not copied. The clauses removed or shortened after the snapshot keep their literals in the allocator,
+//so only the references and the sizes of the clauses are recorded
+type Snapshot struct {
+	numVars      int
+	trail        []Lit
+	clauses      []ClauseReference
+	learnts      []ClauseReference
+	sizes        []int //The sizes of the clauses and then the learnt clauses
+	ok           bool
+	scopes       []Lit
+	decision     []bool
+	activation   []bool
+	userPolarity []LitBool
+	frozen       []bool
+	eliminated   []bool
+	witness      []bool
+	elimStack    []elimEntry
+	elimDone     bool
+	blockedDone  bool
+}
+
+//Snapshot saves the current state of the solver
+//The state includes the clauses, the learnt clauses, the assignments at level 0 and the scopes
+func (s *Solver) Snapshot() *Snapshot {
+	if s.decisionLevel() != 0 {
+		panic(fmt.Errorf("The decision level is not zero: %d", s.decisionLevel()))
+	}
+	return &Snapshot{
+		numVars:      s.NumVars(),
+		trail:        append([]Lit(nil), s.trail...),
+		clauses:      append([]ClauseReference(nil), s.clauses...),
+		learnts:      append([]ClauseReference(nil), s.learnts...),
+		sizes:        s.clauseSizes(),
+		ok:           s.ok,
+		scopes:       append([]Lit(nil), s.scopes...),
+		decision:     append([]bool(nil), s.decision...),
+		activation:   append([]bool(nil), s.activation...),
+		userPolarity: append([]LitBool(nil), s.userPolarity...),
+		frozen:       append([]bool(nil), s.frozen...),
+		eliminated:   append([]bool(nil), s.eliminated...),
+		witness:      append([]bool(nil), s.witness...),
+		elimStack:    append([]elimEntry(nil), s.elimStack...),
+		elimDone:     s.elimDone,
+		blockedDone:  s.blockedDone,
+	}
+}
+
+//Restore brings the solver back to the state saved by Snapshot
+//The snapshot can be restored any number of times
+//The clauses added after the snapshot are removed and the clauses removed or shortened after it are brought back.
+//The activities, the polarities and the counters of Statistics other than the clause counters are kept
+//The functions registered by SetTerminate, SetLearn and SetImport are kept
+//The external propagator is disconnected, and the proof and the clause store are no longer written
+func (s *Solver) Restore(snapshot *Snapshot) {
+	if s.proof != nil {
+		s.proof.flush()
+	}
+	s.proof, s.clauseStore, s.clauseStoreErr = nil, nil, nil
+	s.propagator = nil
+	for _, p := range s.trail {
+		s.assigns[p.Var()] = LitBoolUndef
+		s.varData[p.Var()] = *NewVarData(ClaRefUndef, 0)
+	}
+	s.trail, s.trailLim, s.qhead = s.trail[:0], s.trailLim[:0], 0
+
+	//The variables added after the snapshot are dropped
+	n := snapshot.numVars
+	s.nextVar = Var(n)
+	s.assigns, s.polarity, s.varData, s.seen = s.assigns[:n], s.polarity[:n], s.varData[:n], s.seen[:n]
+	s.unitID, s.observed = s.unitID[:n], make([]bool, n)
+	s.decision = append(s.decision[:0], snapshot.decision...)
+	s.activation = append(s.activation[:0], snapshot.activation...)
+	s.userPolarity = append(s.userPolarity[:0], snapshot.userPolarity...)
+	s.frozen = append(s.frozen[:0], snapshot.frozen...)
+	s.eliminated = append(s.eliminated[:0], snapshot.eliminated...)
+	s.witness = append(s.witness[:0], snapshot.witness...)
+	s.elimStack = append(s.elimStack[:0], snapshot.elimStack...)
+	s.elimDone, s.blockedDone = snapshot.elimDone, snapshot.blockedDone
+	s.scopes = append(s.scopes[:0], snapshot.scopes...)
+	s.model, s.conflict, s.assumptions = s.model[:0], s.conflict[:0], s.assumptions[:0]
+
+	//The clauses of the snapshot get back their removed literals and the other clauses are removed
+	for _, refs := range [][]ClauseReference{s.clauses, s.learnts} {
+		for _, cr := range refs {
+			s.claAllocator.Clauses[cr].SetMark(DeletedMark)
+		}
+	}
+	s.clauses = append(s.clauses[:0], snapshot.clauses...)
+	s.learnts = append(s.learnts[:0], snapshot.learnts...)
+	s.watches = NewWatches()
+	for v := 0; v < n; v++ {
+		s.watches.Init(Var(v))
+	}
+	s.statistics.NumClauses, s.statistics.NumLearnts = 0, 0
+	for i, cr := range append(append([]ClauseReference(nil), s.clauses...), s.learnts...) {
+		c := s.claAllocator.Clauses[cr]
+		c.SetMark(ExistMark)
+		c.header.Size = snapshot.sizes[i]
+		c.header.Learnt = i >= len(s.clauses)
+		if err := s.attachClause(cr); err != nil {
+			panic(err)
+		}
+	}
+
+	activity := s.varOrder.activity[:n]
+	s.varOrder = &Heap{indices: make([]int, n), activity: activity}
+	for v := range s.varOrder.indices {
+		s.varOrder.indices[v] = -1
+	}
+	for v := 0; v < n; v++ {
+		s.insertVarOrder(Var(v))
+	}
+
+	//The watches are rebuilt, so the level 0 assignments are propagated again from the start
+	s.ok = snapshot.ok
+	for _, p := range snapshot.trail {
+		if s.valueLit(p) == LitBoolUndef {
+			s.uncheckedEnqueue(p, ClaRefUndef)
+		}
+	}
+	if s.ok && s.propagate() != ClaRefUndef {
+		s.ok = false
+	}
+}
+
+//clauseSizes returns the sizes of the clauses and then the learnt clauses
+func (s *Solver) clauseSizes() []int {
+	sizes := make([]int, 0, len(s.clauses)+len(s.learnts))
+	for _, refs := range [][]ClauseReference{s.clauses, s.learnts} {
+		for _, cr := range refs {
+			sizes = append(sizes, s.claAllocator.Clauses[cr].Size())
+		}
+	}
+	return sizes
+}
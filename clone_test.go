@@ -0,0 +1,145 @@
+package gatosat
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestClone(t *testing.T) {
+	s := NewSolver()
+	pigeonHole(s, 5, 5)
+	if status := s.Solve(); status != LitBoolTrue {
+		t.Fatalf("The solver returns a wrong value: %v", status)
+	}
+
+	//Force each pigeon into a different hole in each copy
+	clones := make([]*Solver, 5)
+	for i := range clones {
+		clones[i] = s.Clone()
+		clones[i].AddClause([]Lit{*NewLit(Var(i), false)})
+	}
+	var wg sync.WaitGroup
+	statuses := make([]LitBool, len(clones))
+	for i := range clones {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			statuses[i] = clones[i].Solve()
+		}(i)
+	}
+	wg.Wait()
+	for i, c := range clones {
+		if statuses[i] != LitBoolTrue || c.Value(Var(i)) != LitBoolTrue {
+			t.Fatalf("The copy %d returns a wrong value: %v", i, statuses[i])
+		}
+	}
+
+	//The copies don't affect the original solver
+	s.AddClause([]Lit{*NewLit(Var(0), true)})
+	if status := s.Solve(); status != LitBoolTrue || s.Value(Var(0)) != LitBoolFalse {
+		t.Fatalf("The solver returns a wrong value after cloning: %v", status)
+	}
+	if clones[0].Solve() != LitBoolTrue || clones[0].Value(Var(0)) != LitBoolTrue {
+		t.Fatal("The copy is affected by the original solver")
+	}
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	s := NewSolver()
+	pigeonHole(s, 5, 5)
+	snapshot := s.Snapshot()
+	numClauses := s.NumClauses()
+
+	pigeonHole(s, 6, 5)
+	if status := s.Solve(); status != LitBoolFalse {
+		t.Fatalf("The solver returns a wrong value: %v", status)
+	}
+	for i := 0; i < 2; i++ {
+		s.Restore(snapshot)
+		if s.NumClauses() != numClauses || s.NumVars() != 25 {
+			t.Fatalf("The state is not restored: %d clauses %d vars", s.NumClauses(), s.NumVars())
+		}
+		if status := s.Solve(); status != LitBoolTrue {
+			t.Fatalf("The solver returns a wrong value after restoring: %v", status)
+		}
+		s.AddClause([]Lit{})
+	}
+}
+
+func TestSnapshotRestoreRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(7))
+	numVars := 10
+	for i := 0; i < 200; i++ {
+		opts, err := PresetOptions("industrial")
+		if err != nil {
+			t.Fatal(err)
+		}
+		opts.Elimination = i%2 == 0
+		s, err := NewSolverWithOptions(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for v := 0; v < numVars; v++ {
+			s.NewVar()
+		}
+		//Long clauses are shortened by the units added later
+		var clauses [][]Lit
+		for _, c := range randomClauses(rnd, numVars, 1+rnd.Intn(30)) {
+			clauses = append(clauses, append(c, randomClauses(rnd, numVars, 1)[0]...))
+		}
+		for _, c := range clauses {
+			s.AddClause(append([]Lit(nil), c...))
+		}
+		s.Solve()
+		snapshot := s.Snapshot()
+		original := clauseStrings(s)
+
+		//The new clauses shorten and remove the clauses of the snapshot and add variables
+		for round := 0; round < 3; round++ {
+			v := s.NewVar()
+			for _, c := range randomClauses(rnd, numVars, 1+rnd.Intn(10)) {
+				s.AddClause(append(c, *NewLit(v, rnd.Intn(2) == 0)))
+			}
+			s.AddClause(randomClauses(rnd, numVars, 1)[0][:1])
+			s.subsume()
+			s.Solve()
+		}
+		s.Restore(snapshot)
+		if err := s.CheckInvariants(); err != nil {
+			t.Fatalf("The invariant is violated after restoring: %v", err)
+		}
+		if restored := clauseStrings(s); !reflect.DeepEqual(restored, original) {
+			t.Fatalf("The clauses are not restored: %v (expected %v)", restored, original)
+		}
+		if s.NumVars() != numVars {
+			t.Fatalf("The variables are not restored: %d", s.NumVars())
+		}
+		status := s.Solve()
+		if expected := bruteForce(numVars, clauses, nil); status != expected {
+			t.Fatalf("The solver returns a wrong value after restoring: %v (expected %v) clauses %v", status, expected, clauses)
+		}
+		if status == LitBoolTrue {
+			checkModel(t, s.Model(), clauses, nil)
+		}
+	}
+}
+
+//clauseStrings returns the sorted literals of the problem clauses and the learnt clauses
+func clauseStrings(s *Solver) []string {
+	var clauses []string
+	for _, cr := range append(append([]ClauseReference(nil), s.clauses...), s.learnts...) {
+		c := s.claAllocator.GetClause(cr)
+		var lits []int
+		for k := 0; k < c.Size(); k++ {
+			lits = append(lits, c.Data[k].Dimacs())
+		}
+		sort.Ints(lits)
+		clauses = append(clauses, fmt.Sprint(lits))
+	}
+	sort.Strings(clauses)
+	return clauses
+}
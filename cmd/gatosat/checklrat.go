@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/togatoga/gatosat/lrat"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	//CheckLRATCommand checks an LRAT proof of the unsatisfiability of a cnf file
+	CheckLRATCommand   = kingpin.Command("check-lrat", "Check an LRAT proof of the unsatisfiability of a cnf file")
+	CheckLRATInputFile = CheckLRATCommand.Arg("input-file", "Input cnf file").Required().File()
+	CheckLRATProofFile = CheckLRATCommand.Arg("proof-file", "LRAT proof file").Required().File()
+)
+
+func runCheckLRAT() int {
+	inFp := *CheckLRATInputFile
+	defer inFp.Close()
+	proofFp := *CheckLRATProofFile
+	defer proofFp.Close()
+
+	clauses, err := lrat.ReadCNF(inFp)
+	if err != nil {
+		fmt.Println("c ERROR:", err)
+		return NOTVERIFIEDEXITCODE
+	}
+	checker := lrat.NewChecker(clauses)
+	err = checker.Check(proofFp)
+	if *Verbose {
+		fmt.Printf("c added: %d deleted: %d\n", checker.NumAdded, checker.NumDeleted)
+	}
+	if err != nil {
+		fmt.Println("c", err)
+		fmt.Println("s NOT VERIFIED")
+		return NOTVERIFIEDEXITCODE
+	}
+	fmt.Println("s VERIFIED")
+	return VERIFIEDEXITCODE
+}
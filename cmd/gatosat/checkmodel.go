@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/togatoga/gatosat"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	//CheckModelCommand checks a model of a cnf file
+	CheckModelCommand   = kingpin.Command("check-model", "Check that a model satisfies a cnf file")
+	CheckModelInputFile = CheckModelCommand.Arg("input-file", "Input cnf file").Required().File()
+	CheckModelModelFile = CheckModelCommand.Arg("model-file", "Model file (the output of the solver)").Required().File()
+)
+
+func runCheckModel() int {
+	inFp := *CheckModelInputFile
+	defer inFp.Close()
+	modelFp := *CheckModelModelFile
+	defer modelFp.Close()
+
+	_, clauses, err := gatosat.ReadCNF(inFp)
+	if err != nil {
+		fmt.Println("c ERROR:", err)
+		return NOTVERIFIEDEXITCODE
+	}
+	model, err := gatosat.ReadModel(modelFp)
+	if err != nil {
+		fmt.Println("c ERROR:", err)
+		return NOTVERIFIEDEXITCODE
+	}
+	if err := gatosat.CheckModel(clauses, model); err != nil {
+		fmt.Println("c", err)
+		fmt.Println("s MODEL NOT VERIFIED")
+		return NOTVERIFIEDEXITCODE
+	}
+	fmt.Println("s MODEL VERIFIED")
+	return VERIFIEDEXITCODE
+}
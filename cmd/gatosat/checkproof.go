@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/togatoga/gatosat"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+const (
+	// VERIFIEDEXITCODE is the exit code for a verified proof
+	VERIFIEDEXITCODE = 0
+	// NOTVERIFIEDEXITCODE is the exit code for a proof which is not verified
+	NOTVERIFIEDEXITCODE = 1
+)
+
+var (
+	//CheckProofCommand checks a DRAT proof of the unsatisfiability of a cnf file
+	CheckProofCommand   = kingpin.Command("check-proof", "Check a DRAT proof (textual or binary) of the unsatisfiability of a cnf file")
+	CheckProofInputFile = CheckProofCommand.Arg("input-file", "Input cnf file").Required().File()
+	CheckProofProofFile = CheckProofCommand.Arg("proof-file", "DRAT proof file").Required().File()
+	CheckProofCoreFile  = CheckProofCommand.Flag("core", "Write the clauses used by the proof into the file").PlaceHolder("FILE").String()
+	CheckProofLRATFile  = CheckProofCommand.Flag("lrat", "Write the verified proof in LRAT format into the file").PlaceHolder("FILE").String()
+)
+
+//writeFile creates the file and writes into it with the function
+func writeFile(file string, write func(*os.File) error) error {
+	fp, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := write(fp); err != nil {
+		fp.Close()
+		return err
+	}
+	return fp.Close()
+}
+
+func runCheckProof() int {
+	inFp := *CheckProofInputFile
+	defer inFp.Close()
+	proofFp := *CheckProofProofFile
+	defer proofFp.Close()
+
+	numVars, clauses, err := gatosat.ReadCNF(inFp)
+	if err != nil {
+		fmt.Println("c ERROR:", err)
+		return NOTVERIFIEDEXITCODE
+	}
+	checker := gatosat.NewDratChecker(numVars, clauses)
+	if err := checker.ReadProof(proofFp); err != nil {
+		fmt.Println("c ERROR:", err)
+		return NOTVERIFIEDEXITCODE
+	}
+	err = checker.Check()
+	if *Verbose {
+		fmt.Printf("c lemmas: %d core lemmas: %d RAT lemmas: %d ignored deletions: %d\n",
+			checker.NumLemmas, checker.NumCoreLemmas, checker.NumRATLemmas, checker.NumIgnoredDeletion)
+	}
+	if err != nil {
+		fmt.Println("c", err)
+		fmt.Println("s NOT VERIFIED")
+		return NOTVERIFIEDEXITCODE
+	}
+	if *CheckProofCoreFile != "" {
+		if err := writeFile(*CheckProofCoreFile, func(fp *os.File) error { return checker.WriteCore(fp) }); err != nil {
+			fmt.Println("c ERROR:", err)
+		}
+	}
+	if *CheckProofLRATFile != "" {
+		if err := writeFile(*CheckProofLRATFile, func(fp *os.File) error { return checker.WriteLRAT(fp) }); err != nil {
+			fmt.Println("c ERROR:", err)
+		}
+	}
+	fmt.Println("s VERIFIED")
+	return VERIFIEDEXITCODE
+}
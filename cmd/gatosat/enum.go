@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/togatoga/gatosat"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	//EnumCommand enumerates the models of a cnf file
+	EnumCommand    = kingpin.Command("enum", "Enumerate the models of a cnf file")
+	EnumInputFile  = EnumCommand.Arg("input-file", "Input cnf file for enumerating").Required().File()
+	EnumLimit      = EnumCommand.Flag("limit", "The maximum number of the models (0 means no limit)").Int()
+	EnumProjection = EnumCommand.Flag("projection", "Comma separated list of the projected variables (e.g. 1,2,5). All variables are projected if it is empty").String()
+	EnumCubes      = EnumCommand.Flag("cubes", "Generalize the models to partial assignments").Bool()
+)
+
+func parseProjection(projection string) ([]gatosat.Var, error) {
+	var vars []gatosat.Var
+	for _, field := range strings.Split(projection, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		x, err := strconv.Atoi(field)
+		if err != nil || x <= 0 {
+			return nil, fmt.Errorf("The projected variable is invalid: %s", field)
+		}
+		vars = append(vars, gatosat.Var(x-1))
+	}
+	return vars, nil
+}
+
+func runEnum() int {
+	inFp := *EnumInputFile
+	defer inFp.Close()
+
+	solver, err := newSolver()
+	if err != nil {
+		fmt.Println("c ERROR:", err)
+		return UNKNOWNEXITCODE
+	}
+	ctx, cancel := newSolveContext(*CPUTimeLimit)
+	defer cancel()
+	solver.SetTerminate(func() bool {
+		return ctx.Err() != nil
+	})
+
+	if err := gatosat.ParseDimacs(bufio.NewScanner(inFp), solver); err != nil {
+		fmt.Println("c ERROR:", err)
+		return UNKNOWNEXITCODE
+	}
+	projection, err := parseProjection(*EnumProjection)
+	if err != nil {
+		fmt.Println("c ERROR:", err)
+		return UNKNOWNEXITCODE
+	}
+	for _, v := range projection {
+		for int(v) >= solver.NumVars() {
+			solver.NewVar()
+		}
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+	printCube := func(cube []gatosat.Lit) bool {
+		out.WriteString("v")
+		for _, p := range cube {
+			fmt.Fprintf(out, " %d", p.Dimacs())
+		}
+		out.WriteString(" 0\n")
+		out.Flush()
+		return true
+	}
+	var count int
+	var status gatosat.LitBool
+	if *EnumCubes {
+		count, status = solver.EnumerateCubes(projection, *EnumLimit, printCube)
+	} else {
+		count, status = solver.EnumerateModels(projection, *EnumLimit, printCube)
+	}
+
+	fmt.Fprintf(out, "c models: %d\n", count)
+	if status == gatosat.LitBoolFalse {
+		fmt.Fprintln(out, "c ALL MODELS FOUND")
+	} else {
+		fmt.Fprintln(out, "c INDETERMINATE")
+	}
+	if count > 0 {
+		return SATEXITCODE
+	} else if status == gatosat.LitBoolFalse {
+		return UNSATEXITCODE
+	}
+	return UNKNOWNEXITCODE
+}
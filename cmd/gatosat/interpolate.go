@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/togatoga/gatosat"
+	"github.com/togatoga/gatosat/interpolant"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	//InterpolateCommand computes a Craig interpolant of two cnf files
+	InterpolateCommand = kingpin.Command("interpolate", "Compute a Craig interpolant of A and B if they are unsatisfiable together")
+	InterpolateAFile   = InterpolateCommand.Arg("a-file", "Input cnf file of A").Required().File()
+	InterpolateBFile   = InterpolateCommand.Arg("b-file", "Input cnf file of B").Required().File()
+	InterpolateSystem  = InterpolateCommand.Flag("system", "The system of the partial interpolants").Default("mcmillan").Enum("mcmillan", "pudlak")
+	InterpolateFormat  = InterpolateCommand.Flag("format", "The format of the interpolant (ASCII AIGER or DIMACS by Tseitin encoding)").Default("aag").Enum("aag", "cnf")
+	InterpolateOutput  = InterpolateCommand.Flag("output", "Write the interpolant into the file instead of the standard output").PlaceHolder("FILE").String()
+)
+
+func runInterpolate() int {
+	numVarsA, a, err := readCNFFile(*InterpolateAFile)
+	if err != nil {
+		fmt.Println("c ERROR:", err)
+		return UNKNOWNEXITCODE
+	}
+	numVarsB, b, err := readCNFFile(*InterpolateBFile)
+	if err != nil {
+		fmt.Println("c ERROR:", err)
+		return UNKNOWNEXITCODE
+	}
+	numVars := numVarsA
+	if numVarsB > numVars {
+		numVars = numVarsB
+	}
+	system := interpolant.McMillan
+	if *InterpolateSystem == "pudlak" {
+		system = interpolant.Pudlak
+	}
+	opts, err := solverOptions()
+	if err != nil {
+		fmt.Println("c ERROR:", err)
+		return UNKNOWNEXITCODE
+	}
+	solver, err := gatosat.NewSolverWithOptions(opts)
+	if err != nil {
+		fmt.Println("c ERROR:", err)
+		return UNKNOWNEXITCODE
+	}
+	ctx, cancel := newSolveContext(*CPUTimeLimit)
+	defer cancel()
+
+	g, root, status, err := interpolant.Compute(ctx, solver, numVars, a, b, system)
+	if err != nil {
+		fmt.Println("c ERROR:", err)
+		return UNKNOWNEXITCODE
+	}
+	switch status {
+	case gatosat.LitBoolTrue:
+		fmt.Println("s SATISFIABLE")
+		return SATEXITCODE
+	case gatosat.LitBoolUndef:
+		fmt.Println("s INDETERMINATE")
+		return UNKNOWNEXITCODE
+	}
+	fmt.Println("s UNSATISFIABLE")
+	fmt.Printf("c interpolant: %d inputs %d gates\n", len(g.Inputs(root)), g.NumGates(root))
+
+	write := func(fp *os.File) error {
+		if *InterpolateFormat == "aag" {
+			return g.WriteAIGER(fp, root)
+		}
+		//The gates get the variables after the variables of A and B
+		clauses := g.CNF(root, gatosat.Var(numVars))
+		numGates := g.NumGates(root)
+		return gatosat.WriteDimacs(fp, numVars+numGates, clauses)
+	}
+	if *InterpolateOutput != "" {
+		err = writeFile(*InterpolateOutput, write)
+	} else {
+		err = write(os.Stdout)
+	}
+	if err != nil {
+		fmt.Println("c ERROR:", err)
+		return UNKNOWNEXITCODE
+	}
+	return UNSATEXITCODE
+}
+
+//readCNFFile reads the cnf file and closes it
+func readCNFFile(fp *os.File) (int, [][]gatosat.Lit, error) {
+	defer fp.Close()
+	return gatosat.ReadCNF(fp)
+}
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime/pprof"
+	"syscall"
+	"time"
+
+	"github.com/togatoga/gatosat"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+const (
+	// SATEXITCODE is the exit code for UNKNOWN
+	SATEXITCODE = 10
+	// UNSATEXITCODE is the exit code for UNKNOWN
+	UNSATEXITCODE = 20
+	// UNKNOWNEXITCODE is the exit code for UNKNOWN
+	UNKNOWNEXITCODE = 0
+	// WRONGMODELEXITCODE is the exit code for a model which doesn't satisfy the problem
+	WRONGMODELEXITCODE = 1
+)
+
+var CurrentTime time.Time
+
+var (
+	//DebugMode is an option that solver validates its invariants during the search
+	DebugMode = kingpin.Flag("debug", "Debug mode (validate the invariants of the solver every 1000 conflicts and at every restart)").Short('d').Bool()
+	//Verbose is an option that solver showes extra information
+	Verbose      = kingpin.Flag("verbose", "Vervosity mode").Short('v').Default("true").Bool()
+	CPUTimeLimit = kingpin.Flag("cpu-time-limit", "Limit on CPU time allowed in seconds").Int()
+	Profile      = kingpin.Flag("profile", "Profiler file(pprof)").Short('p').String()
+
+	//SolveCommand is the default command which solves a cnf file
+	SolveCommand = kingpin.Command("solve", "Solve a cnf file (default)").Default()
+	InputFile    = SolveCommand.Arg("input-file", "Input cnf file for solving").Required().File()
+	OutputFile   = SolveCommand.Arg("output-file", "Output result file").String()
+	//ProofFile is the file the DRAT proof is written into
+	ProofFile = SolveCommand.Flag("proof", "Write a DRAT proof into the file").PlaceHolder("FILE").String()
+	//ProofFormat is the format of the proof
+	ProofFormat = SolveCommand.Flag("proof-format", "Format of the proof (drat, binary-drat, lrat)").Default("drat").Enum("drat", "binary-drat", "lrat")
+	//CheckModel is an option that solver verifies the model against a copy of the original clauses
+	CheckModel = SolveCommand.Flag("check-model", "Verify the model against the original clauses").Default("true").Bool()
+	//CheckModelOnDisk is an option that solver keeps the copy of the original clauses in a temporary file
+	CheckModelOnDisk = SolveCommand.Flag("check-model-on-disk", "Keep the original clauses for --check-model in a temporary file").Bool()
+	//CoreFile is the file the unsat core is written into
+	CoreFile = SolveCommand.Flag("core", "Write an unsat core into the file in DIMACS format if the problem is unsatisfiable").PlaceHolder("FILE").String()
+	//ShrinkCore is an option that solver shrinks the unsat core by solving the core again
+	ShrinkCore = SolveCommand.Flag("shrink-core", "Shrink the unsat core by solving the core again until it no longer shrinks").Bool()
+)
+
+var proofFormats = map[string]gatosat.ProofFormat{
+	"drat":        gatosat.ProofDRAT,
+	"binary-drat": gatosat.ProofBinaryDRAT,
+	"lrat":        gatosat.ProofLRAT,
+}
+
+func printProblemStatistics(s *gatosat.Solver) {
+	fmt.Printf("c ============================[ Problem Statistics ]=============================\n")
+	fmt.Printf("c |                                                                             |\n")
+	fmt.Printf("c |  Number of variables:  %12d                                         |\n", s.NumVars())
+	fmt.Printf("c |  Number of clauses:    %12d                                         |\n", s.NumClauses())
+	fmt.Printf("c ================================================================================\n")
+}
+
+func printStatistics(s *gatosat.Solver) {
+	stats := s.Statistics()
+	elapsedTimeSeconds := time.Now().Sub(CurrentTime).Seconds()
+	fmt.Printf("c ================================================================================\n")
+	fmt.Printf("c restarts: %12d\n", stats.RestartCount)
+	fmt.Printf("c conflicts: %12d (%.02f / sec)\n", stats.ConflictCount, float64(stats.ConflictCount)/elapsedTimeSeconds)
+	fmt.Printf("c decisions: %12d (%.02f / sec)\n", stats.DecisionCount, float64(stats.DecisionCount)/elapsedTimeSeconds)
+	fmt.Printf("c propagations: %12d (%.02f / sec)\n", stats.PropagationCount, float64(stats.PropagationCount)/elapsedTimeSeconds)
+	fmt.Printf("c reduce DB: %12d\n", stats.ReduceDBCount)
+	fmt.Printf("c removed clause: %12d\n", stats.RemovedClauseCount)
+	fmt.Printf("c eliminated vars: %12d\n", stats.EliminatedVarCount)
+	fmt.Printf("c substituted vars: %12d\n", stats.SubstitutedVarCount)
+	fmt.Printf("c blocked clauses: %12d\n", stats.BlockedClauseCount)
+	fmt.Printf("c covered clauses: %12d\n", stats.CoveredClauseCount)
+	fmt.Printf("c subsumed clauses: %12d\n", stats.SubsumedClauseCount)
+	fmt.Printf("c strengthened clauses: %12d\n", stats.StrengthenedClauseCount)
+	fmt.Printf("c probed literals: %12d (%d failed, %d hyper-binary resolvents, %d propagations)\n", stats.ProbedLiteralCount, stats.FailedLiteralCount, stats.HyperBinaryCount, stats.ProbePropagationCount)
+	fmt.Printf("c cpu time: %12f\n", elapsedTimeSeconds)
+}
+
+//newSolveContext returns a context which is cancelled when the cpu time limit is exceeded
+//or the process receives SIGINT/SIGTERM
+func newSolveContext(limitTimeSeconds int) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	if limitTimeSeconds > 0 {
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(limitTimeSeconds)*time.Second)
+	}
+	c := make(chan os.Signal, 2)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-c:
+			fmt.Println("c INTERUPT")
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(c)
+		cancel()
+	}
+}
+
+func printModel(s *gatosat.Solver, numVars int) {
+	model := s.Model()
+	fmt.Print("v ")
+	for i := 0; i < numVars; i++ {
+		if model[i] == gatosat.LitBoolTrue {
+			fmt.Printf("%d ", i+1)
+		} else {
+			fmt.Printf("%d ", -(i + 1))
+		}
+	}
+	fmt.Print("0\n")
+}
+
+func writeOutputFile(file string, s *gatosat.Solver, numVars int, status gatosat.LitBool) error {
+	var fp *os.File
+
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		fp, err = os.Create(file)
+		if err != nil {
+			return err
+		}
+	} else {
+		fp, err = os.Create(file)
+		if err != nil {
+			return err
+		}
+	}
+	defer fp.Close()
+
+	if status == gatosat.LitBoolTrue {
+		model := s.Model()
+		for i := 0; i < numVars; i++ {
+			if model[i] == gatosat.LitBoolTrue {
+				fp.WriteString(fmt.Sprintf("%d ", i+1))
+			} else {
+				fp.WriteString(fmt.Sprintf("%d ", -(i + 1)))
+			}
+		}
+		fp.WriteString("0\n")
+	} else if status == gatosat.LitBoolFalse {
+		fp.WriteString("UNSAT")
+	}
+	return nil
+}
+
+func init() {
+	CurrentTime = time.Now()
+}
+
+//newSolver returns a solver with the options given on the command line
+func newSolver() (*gatosat.Solver, error) {
+	opts, err := solverOptions()
+	if err != nil {
+		return nil, err
+	}
+	return gatosat.NewSolverWithOptions(opts)
+}
+
+func run() int {
+	//input
+	inFp := *InputFile
+	defer inFp.Close()
+	in := bufio.NewScanner(inFp)
+
+	//Start profile
+	if *Profile != "" {
+		f, err := os.Create(*Profile)
+		if err != nil {
+			panic(err)
+		}
+		pprof.StartCPUProfile(f)
+	}
+
+	solver, err := newSolver()
+	if err != nil {
+		fmt.Println("c ERROR:", err)
+		return UNKNOWNEXITCODE
+	}
+	ctx, cancel := newSolveContext(*CPUTimeLimit)
+	defer cancel()
+
+	if *CoreFile != "" && *ProofFile != "" {
+		fmt.Println("c ERROR: --core can't be used with --proof")
+		return UNKNOWNEXITCODE
+	}
+	if *ProofFile != "" {
+		proofFp, err := os.Create(*ProofFile)
+		if err != nil {
+			fmt.Println("c ERROR:", err)
+			return UNKNOWNEXITCODE
+		}
+		defer proofFp.Close()
+		solver.SetProof(proofFp, proofFormats[*ProofFormat])
+	}
+
+	if *CheckModel {
+		if *CheckModelOnDisk {
+			store, err := gatosat.NewFileClauseStore("")
+			if err != nil {
+				fmt.Println("c ERROR:", err)
+				return UNKNOWNEXITCODE
+			}
+			defer store.Close()
+			solver.SetClauseStore(store)
+		} else {
+			solver.SetClauseStore(gatosat.NewMemoryClauseStore())
+		}
+	}
+
+	var status gatosat.LitBool
+	var numVars int
+	if *CoreFile != "" {
+		//The clauses are added with selector variables after the variables of the problem
+		var clauses [][]gatosat.Lit
+		numVars, clauses, err = gatosat.ReadCNF(inFp)
+		if err != nil {
+			fmt.Println("c ERROR:", err)
+			return UNKNOWNEXITCODE
+		}
+		for solver.NumVars() < numVars {
+			solver.NewVar()
+		}
+		var core []int
+		core, status = solver.UnsatCore(ctx, clauses, *ShrinkCore)
+		if status == gatosat.LitBoolFalse {
+			coreClauses := make([][]gatosat.Lit, len(core))
+			for i, c := range core {
+				coreClauses[i] = clauses[c]
+			}
+			if *Verbose {
+				fmt.Printf("c core clauses: %d / %d\n", len(core), len(clauses))
+			}
+			err := writeFile(*CoreFile, func(fp *os.File) error { return gatosat.WriteDimacs(fp, numVars, coreClauses) })
+			if err != nil {
+				fmt.Println("c ERROR:", err)
+			}
+		}
+	} else {
+		err = gatosat.ParseDimacs(in, solver)
+		if err != nil {
+			return UNKNOWNEXITCODE
+		}
+		numVars = solver.NumVars()
+		if *Verbose {
+			printProblemStatistics(solver)
+		}
+		status = solver.SolveContext(ctx)
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		fmt.Println("c TIMEOUT")
+	}
+	if err := solver.FlushProof(); err != nil {
+		fmt.Println("c ERROR: failed to write the proof:", err)
+	}
+	//End profile
+	if *Profile != "" {
+		pprof.StopCPUProfile()
+	}
+
+	if *Verbose {
+		printStatistics(solver)
+	}
+
+	if status == gatosat.LitBoolTrue && *CheckModel {
+		if err := solver.VerifyModel(); err != nil {
+			fmt.Fprintln(os.Stderr, "c ERROR: the model is wrong:", err)
+			return WRONGMODELEXITCODE
+		}
+	}
+
+	if status == gatosat.LitBoolTrue {
+		fmt.Println("\ns SATISFIABLE")
+		printModel(solver, numVars)
+	} else if status == gatosat.LitBoolFalse {
+		fmt.Println("\ns UNSATISFIABLE")
+	} else {
+		fmt.Println("\ns INDETERMINATE")
+	}
+
+	if OutputFile != nil {
+		writeOutputFile(*OutputFile, solver, numVars, status)
+	}
+	if status == gatosat.LitBoolTrue {
+		return SATEXITCODE
+	} else if status == gatosat.LitBoolFalse {
+		return UNSATEXITCODE
+	}
+
+	return UNKNOWNEXITCODE
+}
+
+func main() {
+	kingpin.Version("0.0.1")
+	switch kingpin.Parse() {
+	case SolveCommand.FullCommand():
+		os.Exit(run())
+	case EnumCommand.FullCommand():
+		os.Exit(runEnum())
+	case CheckProofCommand.FullCommand():
+		os.Exit(runCheckProof())
+	case CheckLRATCommand.FullCommand():
+		os.Exit(runCheckLRAT())
+	case CheckModelCommand.FullCommand():
+		os.Exit(runCheckModel())
+	case MUSCommand.FullCommand():
+		os.Exit(runMUS())
+	case MARCOCommand.FullCommand():
+		os.Exit(runMARCO())
+	case InterpolateCommand.FullCommand():
+		os.Exit(runInterpolate())
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/togatoga/gatosat"
+	"github.com/togatoga/gatosat/mus"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	//MARCOCommand enumerates the MUSes and the MCSes of a cnf or gcnf file
+	MARCOCommand   = kingpin.Command("marco", "Enumerate the MUSes and the minimal correction sets of the clauses (cnf) or the groups (gcnf)")
+	MARCOInputFile = MARCOCommand.Arg("input-file", "Input cnf or gcnf file").Required().File()
+	MARCOLimit     = MARCOCommand.Flag("limit", "The maximum number of the MUSes and MCSes (0 means no limit)").Int()
+)
+
+func runMARCO() int {
+	inFp := *MARCOInputFile
+	defer inFp.Close()
+
+	problem, err := mus.ReadProblem(inFp)
+	if err != nil {
+		fmt.Println("c ERROR:", err)
+		return UNKNOWNEXITCODE
+	}
+	//The search statistics of every call to the solvers are too many
+	opts, err := solverOptions()
+	if err != nil {
+		fmt.Println("c ERROR:", err)
+		return UNKNOWNEXITCODE
+	}
+	opts.Verbose = nil
+	mapSolver, err := gatosat.NewSolverWithOptions(opts)
+	if err != nil {
+		fmt.Println("c ERROR:", err)
+		return UNKNOWNEXITCODE
+	}
+	checkSolver, err := gatosat.NewSolverWithOptions(opts)
+	if err != nil {
+		fmt.Println("c ERROR:", err)
+		return UNKNOWNEXITCODE
+	}
+	ctx, cancel := newSolveContext(*CPUTimeLimit)
+	defer cancel()
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+	enumerator := mus.NewEnumerator(mapSolver, checkSolver, problem)
+	status := enumerator.Enumerate(ctx, func(r mus.Result) bool {
+		if r.MUS {
+			out.WriteString("mus")
+		} else {
+			out.WriteString("mcs")
+		}
+		for _, g := range r.Groups {
+			fmt.Fprintf(out, " %d", g)
+		}
+		out.WriteString(" 0\n")
+		out.Flush()
+		return *MARCOLimit <= 0 || enumerator.NumMUS+enumerator.NumMCS < *MARCOLimit
+	})
+
+	fmt.Fprintf(out, "c MUSes: %d MCSes: %d solves: %d\n", enumerator.NumMUS, enumerator.NumMCS, enumerator.NumSolves)
+	if status == gatosat.LitBoolFalse {
+		fmt.Fprintln(out, "c ALL MUSES AND MCSES FOUND")
+	} else {
+		fmt.Fprintln(out, "c INDETERMINATE")
+	}
+	if enumerator.NumMUS > 0 {
+		return UNSATEXITCODE
+	} else if status == gatosat.LitBoolFalse {
+		return SATEXITCODE
+	}
+	return UNKNOWNEXITCODE
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/togatoga/gatosat"
+	"github.com/togatoga/gatosat/mus"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	//MUSCommand extracts a minimal unsatisfiable subset of a cnf or gcnf file
+	MUSCommand    = kingpin.Command("mus", "Extract a minimal unsatisfiable subset of the clauses (cnf) or the groups (gcnf)")
+	MUSInputFile  = MUSCommand.Arg("input-file", "Input cnf or gcnf file").Required().File()
+	MUSOutputFile = MUSCommand.Flag("output", "Write the hard clauses and the clauses of the MUS into the file in DIMACS format").PlaceHolder("FILE").String()
+	MUSRefinement = MUSCommand.Flag("refinement", "Use clause-set refinement").Default("true").Bool()
+	MUSRotation   = MUSCommand.Flag("rotation", "Use recursive model rotation").Default("true").Bool()
+)
+
+func runMUS() int {
+	inFp := *MUSInputFile
+	defer inFp.Close()
+
+	problem, err := mus.ReadProblem(inFp)
+	if err != nil {
+		fmt.Println("c ERROR:", err)
+		return UNKNOWNEXITCODE
+	}
+	//The search statistics of every call to the solver are too many
+	opts, err := solverOptions()
+	if err != nil {
+		fmt.Println("c ERROR:", err)
+		return UNKNOWNEXITCODE
+	}
+	opts.Verbose = nil
+	solver, err := gatosat.NewSolverWithOptions(opts)
+	if err != nil {
+		fmt.Println("c ERROR:", err)
+		return UNKNOWNEXITCODE
+	}
+	ctx, cancel := newSolveContext(*CPUTimeLimit)
+	defer cancel()
+
+	extractor := mus.NewExtractor(solver, problem)
+	extractor.Refinement = *MUSRefinement
+	extractor.Rotation = *MUSRotation
+	groups, status := extractor.Extract(ctx)
+	if *Verbose {
+		fmt.Printf("c groups: %d solves: %d refined: %d rotated: %d cpu time: %f\n", len(problem.Groups),
+			extractor.NumSolves, extractor.NumRefined, extractor.NumRotated, time.Now().Sub(CurrentTime).Seconds())
+	}
+	switch status {
+	case gatosat.LitBoolTrue:
+		fmt.Println("s SATISFIABLE")
+		return SATEXITCODE
+	case gatosat.LitBoolUndef:
+		fmt.Println("s INDETERMINATE")
+		return UNKNOWNEXITCODE
+	}
+
+	fmt.Println("s UNSATISFIABLE")
+	out := bufio.NewWriter(os.Stdout)
+	out.WriteString("v")
+	for _, g := range groups {
+		fmt.Fprintf(out, " %d", g)
+	}
+	out.WriteString(" 0\n")
+	out.Flush()
+	if *MUSOutputFile != "" {
+		clauses := append([][]gatosat.Lit(nil), problem.Hard...)
+		for _, g := range groups {
+			clauses = append(clauses, problem.Groups[g-1]...)
+		}
+		err := writeFile(*MUSOutputFile, func(fp *os.File) error { return gatosat.WriteDimacs(fp, problem.NumVars, clauses) })
+		if err != nil {
+			fmt.Println("c ERROR:", err)
+		}
+	}
+	return UNSATEXITCODE
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/togatoga/gatosat"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	//Preset is the named configuration preset the options start from
+	Preset = kingpin.Flag("preset", "Configuration preset ("+strings.Join(gatosat.PresetNames(), ", ")+")").Default("minisat").Enum(gatosat.PresetNames()...)
+	//ConfigFile is a JSON file which overwrites the options of the preset
+	ConfigFile = kingpin.Flag("config", "JSON configuration file of the solver options").PlaceHolder("FILE").String()
+
+	//optionFlags are applied to the options only when they are given on the command line
+	optionFlags          = map[string]func(*gatosat.SolverOptions){}
+	restartPolicy        = optionFlag("restart", "Restart policy (luby, geometric)").Enum(gatosat.RestartLuby, gatosat.RestartGeometric)
+	restartFirst         = optionFlag("restart-first", "The initial restart limit").Int()
+	restartIncreaseRatio = optionFlag("restart-inc", "The factor with which the restart limit is multiplied in each restart").Float64()
+	varDecayRatio        = optionFlag("var-decay", "The variable activity decay factor").Float64()
+	clauseDecayRatio     = optionFlag("cla-decay", "The clause activity decay factor").Float64()
+	learntSizeFactor     = optionFlag("learnt-size-factor", "The limit on the number of learnt clauses as a factor of the original clauses").Float64()
+	randomVarFreq        = optionFlag("rnd-freq", "The frequency with which the decision heuristic tries to choose a random variable").Float64()
+	seed                 = optionFlag("seed", "The seed for the random variable selection").Float64()
+	elimination          = optionFlag("elim", "Run the bounded variable elimination before the first search").Bool()
+	probing              = optionFlag("probe", "Probe the roots of the binary implication graph for failed literals before the search and at restarts").Bool()
+	substitution         = optionFlag("subst", "Substitute the equivalent literals found in the binary clauses before the search").Bool()
+	blockedClauses       = optionFlag("bce", "Remove the blocked clauses before the first search").Bool()
+	coveredClauses       = optionFlag("cce", "Remove the covered clauses before the first search").Bool()
+	subsumption          = optionFlag("subsume", "Remove the subsumed clauses and strengthen the clauses before the search and periodically").Bool()
+)
+
+func init() {
+	optionFlags["restart"] = func(o *gatosat.SolverOptions) { o.RestartPolicy = *restartPolicy }
+	optionFlags["restart-first"] = func(o *gatosat.SolverOptions) { o.RestartFirst = *restartFirst }
+	optionFlags["restart-inc"] = func(o *gatosat.SolverOptions) { o.RestartIncreaseRatio = *restartIncreaseRatio }
+	optionFlags["var-decay"] = func(o *gatosat.SolverOptions) { o.VarDecayRatio = *varDecayRatio }
+	optionFlags["cla-decay"] = func(o *gatosat.SolverOptions) { o.ClauseDecayRatio = *clauseDecayRatio }
+	optionFlags["learnt-size-factor"] = func(o *gatosat.SolverOptions) { o.LearntSizeFactor = *learntSizeFactor }
+	optionFlags["rnd-freq"] = func(o *gatosat.SolverOptions) { o.RandomVarFreq = *randomVarFreq }
+	optionFlags["seed"] = func(o *gatosat.SolverOptions) { o.Seed = *seed }
+	optionFlags["elim"] = func(o *gatosat.SolverOptions) { o.Elimination = *elimination }
+	optionFlags["subsume"] = func(o *gatosat.SolverOptions) { o.Subsumption = *subsumption }
+	optionFlags["probe"] = func(o *gatosat.SolverOptions) { o.Probing = *probing }
+	optionFlags["subst"] = func(o *gatosat.SolverOptions) { o.Substitution = *substitution }
+	optionFlags["bce"] = func(o *gatosat.SolverOptions) { o.BlockedClauseElimination = *blockedClauses }
+	optionFlags["cce"] = func(o *gatosat.SolverOptions) { o.CoveredClauseElimination = *coveredClauses }
+}
+
+//givenFlags are the names of the option flags given on the command line
+var givenFlags []string
+
+func optionFlag(name, help string) *kingpin.FlagClause {
+	return kingpin.Flag(name, help).Action(func(*kingpin.ParseContext) error {
+		givenFlags = append(givenFlags, name)
+		return nil
+	})
+}
+
+//solverOptions builds the options from the preset, the configuration file and the flags in this order
+func solverOptions() (gatosat.SolverOptions, error) {
+	opts, err := gatosat.PresetOptions(*Preset)
+	if err != nil {
+		return opts, err
+	}
+	if *ConfigFile != "" {
+		f, err := os.Open(*ConfigFile)
+		if err != nil {
+			return opts, err
+		}
+		defer f.Close()
+		if err := opts.LoadOptions(f); err != nil {
+			return opts, err
+		}
+	}
+	for _, name := range givenFlags {
+		optionFlags[name](&opts)
+	}
+	if *Verbose {
+		opts.Verbose = os.Stdout
+	}
+	if *DebugMode && opts.DebugCheckInterval == 0 {
+		opts.DebugCheckInterval = 1000
+	}
+	return opts, nil
+}
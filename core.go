@@ -0,0 +1,73 @@
+package gatosat
+
+import (
+	"context"
+	"fmt"
+)
+
+//UnsatCore adds the clauses to the solver and returns the indices of an unsatisfiable subset of them (an unsat core)
+//Every clause is added with the negation of a fresh selector variable, and the solver is called assuming all selectors,
+//so the selectors in the final conflict tell the clauses used to refute the problem
+//The clauses already added by AddClause are kept as hard clauses, which are never in the core
+//If shrink is true, the solver is called again assuming only the selectors of the core until the core no longer shrinks
+//It returns LitBoolFalse and the core if the problem is unsatisfiable, or LitBoolTrue if it is satisfiable
+//or LitBoolUndef if the search is stopped
+//The selector variables are activation variables, so they are not included in FailedAssumptions and EnumerateModels
+//The clauses stay in the solver after the call, but they are disabled unless the caller assumes their selectors.
+//The selector of the i-th clause is the positive literal of the variable NumVars()+i, where NumVars() is taken before the call
+func (s *Solver) UnsatCore(ctx context.Context, clauses [][]Lit, shrink bool) ([]int, LitBool) {
+	selectors := make([]Lit, len(clauses))
+	index := map[Var]int{}
+	for i, c := range clauses {
+		for _, p := range c {
+			if int(p.Var()) >= s.NumVars() {
+				panic(fmt.Errorf("The literal is not a variable of the solver: %d", p.Var()))
+			}
+		}
+		v := s.NewVar()
+		s.SetDecisionVar(v, false)
+		s.activation[v] = true
+		selectors[i] = *NewLit(v, false)
+		index[v] = i
+		s.AddClause(append(append(make([]Lit, 0, len(c)+1), c...), selectors[i].Flip()))
+	}
+
+	status := s.SolveWithAssumptionsContext(ctx, selectors)
+	if status != LitBoolFalse {
+		return nil, status
+	}
+	core := s.coreFromConflict(index)
+	for shrink && len(core) > 0 {
+		assumptions := make([]Lit, len(core))
+		for i, c := range core {
+			assumptions[i] = selectors[c]
+		}
+		if s.SolveWithAssumptionsContext(ctx, assumptions) != LitBoolFalse {
+			//The search is stopped. The last core is still unsatisfiable
+			break
+		}
+		smaller := s.coreFromConflict(index)
+		if len(smaller) >= len(core) {
+			break
+		}
+		core = smaller
+	}
+	return core, LitBoolFalse
+}
+
+//coreFromConflict returns the indices of the clauses whose selectors are in the final conflict in ascending order
+func (s *Solver) coreFromConflict(index map[Var]int) []int {
+	inCore := map[int]bool{}
+	for _, p := range s.conflict {
+		if i, ok := index[p.Var()]; ok {
+			inCore[i] = true
+		}
+	}
+	var core []int
+	for i := 0; len(core) < len(inCore); i++ {
+		if inCore[i] {
+			core = append(core, i)
+		}
+	}
+	return core
+}
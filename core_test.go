@@ -0,0 +1,64 @@
+package gatosat
+
+import (
+	"context"
+	"testing"
+)
+
+func TestUnsatCore(t *testing.T) {
+	numVars, clauses, _ := pigeonHoleProof(t, 5, 4, ProofDRAT)
+	numPigeonHole := len(clauses)
+	//The clauses of the other variables are never needed for the refutation
+	x, y := *NewLit(Var(numVars), false), *NewLit(Var(numVars+1), false)
+	clauses = append(clauses, []Lit{x, y}, []Lit{x.Flip(), y}, []Lit{y})
+	numVars += 2
+
+	for _, shrink := range []bool{false, true} {
+		s := NewSolver()
+		for i := 0; i < numVars; i++ {
+			s.NewVar()
+		}
+		core, status := s.UnsatCore(context.Background(), clauses, shrink)
+		if status != LitBoolFalse || len(core) == 0 {
+			t.Fatalf("The solver returns a wrong value: %v %v", status, core)
+		}
+		checker := NewSolver()
+		for i := 0; i < numVars; i++ {
+			checker.NewVar()
+		}
+		for i, c := range core {
+			if c >= numPigeonHole || i > 0 && core[i-1] >= c {
+				t.Fatalf("The core has a wrong clause: %v", core)
+			}
+			checker.AddClause(clauses[c])
+		}
+		if status := checker.Solve(); status != LitBoolFalse {
+			t.Fatalf("The core is satisfiable: %v", core)
+		}
+		if len(s.FailedAssumptions()) != 0 {
+			t.Fatalf("The selectors are reported as failed assumptions: %v", s.FailedAssumptions())
+		}
+	}
+
+	//The empty clause is a core by itself
+	s := NewSolver()
+	s.NewVar()
+	a := *NewLit(0, false)
+	core, status := s.UnsatCore(context.Background(), [][]Lit{{a}, {}, {a.Flip()}}, true)
+	if status != LitBoolFalse || len(core) != 1 || core[0] != 1 {
+		t.Fatalf("The core of the empty clause is wrong: %v %v", status, core)
+	}
+
+	s = NewSolver()
+	s.NewVar()
+	if _, status := s.UnsatCore(context.Background(), [][]Lit{{a}}, false); status != LitBoolTrue {
+		t.Fatalf("The solver returns a wrong value: %v", status)
+	}
+	//The clause is enabled only by assuming its selector
+	if status := s.SolveWithAssumptions([]Lit{a.Flip()}); status != LitBoolTrue {
+		t.Fatalf("The clause is not disabled: %v", status)
+	}
+	if status := s.SolveWithAssumptions([]Lit{*NewLit(1, false), a.Flip()}); status != LitBoolFalse {
+		t.Fatalf("The clause is not enabled by the selector: %v", status)
+	}
+}
@@ -1,15 +1,16 @@
-package main
+package gatosat
 
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
 )
 
-func readClause(line string, s *Solver) (lits []Lit, err error) {
+func parseClause(line string) (lits []Lit, err error) {
 	values := strings.Fields(line)
-	if values[len(values)-1] != "0" {
+	if len(values) == 0 || values[len(values)-1] != "0" {
 		return nil, fmt.Errorf("PARSE ERROR! The end of clause is not 0: %s", line)
 	}
 	for i := 0; i < len(values)-1; i++ {
@@ -20,29 +21,59 @@ func readClause(line string, s *Solver) (lits []Lit, err error) {
 		if parsedValue == 0 {
 			return nil, fmt.Errorf("PARSE ERROR! The format of cnf input is worng")
 		}
+		lits = append(lits, *NewLitFromDimacs(parsedValue))
+	}
+	return lits, nil
+}
 
-		value := parsedValue
-		neg := false
-		if parsedValue > 0 {
-			value--
-		} else {
-			neg = true
-			value *= -1
-			value--
-		}
-
-		for value >= s.NumVars() {
+func readClause(line string, s *Solver) (lits []Lit, err error) {
+	lits, err = parseClause(line)
+	if err != nil {
+		return nil, err
+	}
+	for _, lit := range lits {
+		for int(lit.Var()) >= s.NumVars() {
 			s.NewVar()
 		}
-
-		lit := NewLit(Var(value), neg)
-		lits = append(lits, *lit)
 	}
-
 	return lits, nil
 }
 
-func parseDimacs(in *bufio.Scanner, s *Solver) (err error) {
+//ReadCNF reads a problem in DIMACS CNF format and returns the number of the variables and the clauses
+func ReadCNF(r io.Reader) (numVars int, clauses [][]Lit, err error) {
+	in := bufio.NewScanner(r)
+	in.Buffer(make([]byte, 1024*1024), 1024*1024*1024)
+	for in.Scan() {
+		line := strings.TrimSpace(in.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "c") {
+			continue
+		}
+		if strings.HasPrefix(line, "p cnf") {
+			values := strings.Fields(line)
+			if len(values) != 4 {
+				return 0, nil, fmt.Errorf("PARSE ERROR! The problem line is wrong: %s", line)
+			}
+			if numVars, err = strconv.Atoi(values[2]); err != nil {
+				return 0, nil, err
+			}
+			continue
+		}
+		lits, err := parseClause(line)
+		if err != nil {
+			return 0, nil, err
+		}
+		for _, lit := range lits {
+			if int(lit.Var()) >= numVars {
+				numVars = int(lit.Var()) + 1
+			}
+		}
+		clauses = append(clauses, lits)
+	}
+	return numVars, clauses, in.Err()
+}
+
+//ParseDimacs reads a problem in DIMACS CNF format and adds its clauses to the solver
+func ParseDimacs(in *bufio.Scanner, s *Solver) (err error) {
 	vars := 0
 	clauses := 0
 	cnt := 0
@@ -72,9 +103,7 @@ func parseDimacs(in *bufio.Scanner, s *Solver) (err error) {
 			if err != nil {
 				return err
 			}
-			if lits != nil {
-				s.addClause(lits)
-			}
+			s.AddClause(lits)
 		}
 	}
 	if cnt != clauses {
@@ -83,3 +112,13 @@ func parseDimacs(in *bufio.Scanner, s *Solver) (err error) {
 	_ = vars
 	return nil
 }
+
+//WriteDimacs writes the clauses in DIMACS CNF format
+func WriteDimacs(w io.Writer, numVars int, clauses [][]Lit) error {
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "p cnf %d %d\n", numVars, len(clauses))
+	for _, c := range clauses {
+		fmt.Fprintf(out, "%s\n", dimacsString(c))
+	}
+	return out.Flush()
+}
@@ -0,0 +1,645 @@
+package gatosat
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+//dratStep is a step of a DRAT proof
+type dratStep struct {
+	deletion bool
+	lits     []Lit
+	pivot    Lit             //The first literal of the lemma as written in the proof
+	claRef   ClauseReference //The added clause or the deleted clause. ClaRefUndef if the deletion is ignored
+	trailLen int             //The length of the trail before the step
+}
+
+//dratHints is the derivation of a lemma found by the checker
+//rup holds the reasons and the conflicting clause in the order of the trail
+//If the lemma is RAT, rat holds the derivation of the resolvent with each candidate clause
+type dratHints struct {
+	rup []ClauseReference
+	rat []dratResolvent
+}
+
+type dratResolvent struct {
+	candidate ClauseReference
+	rup       []ClauseReference //nil if the resolvent is a tautology
+}
+
+//DratChecker checks a DRAT proof of the unsatisfiability of a formula by backward checking
+//It verifies only the lemmas needed for the conflict (the core), propagating the core clauses first
+//The assignments, the reasons and the propagation are the ones of an internal solver at the level 0
+type DratChecker struct {
+	s           *Solver  //The non-core clauses are in the watches of s
+	coreWatches *Watches //The watches of the core clauses
+	trailPos    []int
+	numPos      int //The number of the literals of the trail whose positions are in trailPos
+	qhead       int //The head of the propagation over the non-core clauses
+	coreHead    int //The head of the propagation over the core clauses
+
+	numClauses int //The number of the clauses of the formula. Their references are 0, 1, ..., numClauses-1
+	core       []bool
+	active     []bool
+	hints      map[ClauseReference]*dratHints
+	steps      []dratStep
+	conflict   ClauseReference //The clause falsified at the top level after the forward pass
+	finalRUP   []ClauseReference
+	checked    bool
+
+	//Counters of the check
+	NumLemmas          int
+	NumCoreLemmas      int
+	NumRATLemmas       int
+	NumIgnoredDeletion int
+}
+
+//NewDratChecker returns a checker for the formula
+func NewDratChecker(numVars int, clauses [][]Lit) *DratChecker {
+	c := &DratChecker{
+		s:           NewSolver(),
+		coreWatches: NewWatches(),
+		hints:       map[ClauseReference]*dratHints{},
+		conflict:    ClaRefUndef,
+	}
+	c.ensureVars(numVars)
+	for _, lits := range clauses {
+		c.newClause(lits)
+	}
+	c.numClauses = len(clauses)
+	return c
+}
+
+func (c *DratChecker) ensureVars(numVars int) {
+	for c.s.NumVars() < numVars {
+		c.coreWatches.Init(c.s.NewVar())
+		c.trailPos = append(c.trailPos, 0)
+	}
+}
+
+//newClause allocates the clause without duplicate literals
+func (c *DratChecker) newClause(lits []Lit) ClauseReference {
+	ps := make([]Lit, 0, len(lits))
+	for _, p := range lits {
+		c.ensureVars(int(p.Var()) + 1)
+		duplicate := false
+		for _, q := range ps {
+			if p == q {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			ps = append(ps, p)
+		}
+	}
+	cr, err := c.s.claAllocator.NewAllocate(ps, false)
+	if err != nil {
+		panic(err)
+	}
+	c.core = append(c.core, false)
+	c.active = append(c.active, false)
+	return cr
+}
+
+//reason returns the clause which propagated the variable
+func (c *DratChecker) reason(x Var) ClauseReference {
+	return c.s.varData[x].Reason
+}
+
+//backtrack unassigns the literals assigned after the first n literals of the trail
+func (c *DratChecker) backtrack(n int) {
+	s := c.s
+	for i := len(s.trail) - 1; i >= n; i-- {
+		x := s.trail[i].Var()
+		s.assigns[x] = LitBoolUndef
+		s.varData[x].Reason = ClaRefUndef
+	}
+	s.trail = s.trail[:n]
+	if c.qhead > n {
+		c.qhead = n
+	}
+	if c.coreHead > n {
+		c.coreHead = n
+	}
+	if c.numPos > n {
+		c.numPos = n
+	}
+}
+
+//watchesOf returns the watches of the core clauses or the other clauses which the clause is in
+func (c *DratChecker) watchesOf(cr ClauseReference) *Watches {
+	if c.core[cr] {
+		return c.coreWatches
+	}
+	return c.s.watches
+}
+
+//markCore marks the clause as core and moves its watchers to the watches of the core clauses
+func (c *DratChecker) markCore(cr ClauseReference) {
+	if c.core[cr] {
+		return
+	}
+	if !c.active[cr] {
+		c.core[cr] = true
+		return
+	}
+	c.detach(cr)
+	c.core[cr] = true
+	c.watch(cr)
+}
+
+//attach watches the clause and returns the clause if it is falsified
+//The non-false literals are moved to the front and a unit clause propagates its literal
+func (c *DratChecker) attach(cr ClauseReference) ClauseReference {
+	s := c.s
+	cla := s.claAllocator.GetClause(cr)
+	lits := cla.Data[:cla.Size()]
+	for ; c.numPos < len(s.trail); c.numPos++ {
+		c.trailPos[s.trail[c.numPos].Var()] = c.numPos
+	}
+	//Watch the non-false literals first, then the false literals assigned last
+	sort.SliceStable(lits, func(i, j int) bool {
+		fi, fj := s.valueLit(lits[i]) == LitBoolFalse, s.valueLit(lits[j]) == LitBoolFalse
+		if fi != fj {
+			return fj
+		}
+		return fi && c.trailPos[lits[i].Var()] > c.trailPos[lits[j].Var()]
+	})
+	c.watch(cr)
+	if len(lits) >= 2 && s.valueLit(lits[1]) != LitBoolFalse {
+		return ClaRefUndef
+	}
+	if len(lits) == 0 || s.valueLit(lits[0]) == LitBoolFalse {
+		return cr
+	}
+	if s.valueLit(lits[0]) == LitBoolUndef {
+		s.uncheckedEnqueue(lits[0], cr)
+	}
+	return ClaRefUndef
+}
+
+//watch adds the watchers of the first two literals of the clause
+func (c *DratChecker) watch(cr ClauseReference) {
+	cla := c.s.claAllocator.GetClause(cr)
+	c.active[cr] = true
+	if cla.Size() >= 2 {
+		first, second := cla.At(0), cla.At(1)
+		ws := c.watchesOf(cr)
+		ws.Append(first.Flip(), NewWatcher(cr, second))
+		ws.Append(second.Flip(), NewWatcher(cr, first))
+	}
+}
+
+func (c *DratChecker) detach(cr ClauseReference) {
+	cla := c.s.claAllocator.GetClause(cr)
+	c.active[cr] = false
+	if cla.Size() >= 2 {
+		first, second := cla.At(0), cla.At(1)
+		ws := c.watchesOf(cr)
+		RemoveWatcher(ws, first.Flip(), NewWatcher(cr, second))
+		RemoveWatcher(ws, second.Flip(), NewWatcher(cr, first))
+	}
+}
+
+//propagate propagates the trail by the watches of the solver and returns a falsified clause
+//The core clauses are propagated first and the other clauses only if the core clauses imply nothing
+func (c *DratChecker) propagate() ClauseReference {
+	s := c.s
+	for {
+		for c.coreHead < len(s.trail) {
+			p := s.trail[c.coreHead]
+			c.coreHead++
+			if confl := s.propagateWatches(p, c.coreWatches); confl != ClaRefUndef {
+				c.qhead, c.coreHead = len(s.trail), len(s.trail)
+				return confl
+			}
+		}
+		if c.qhead == len(s.trail) {
+			return ClaRefUndef
+		}
+		p := s.trail[c.qhead]
+		c.qhead++
+		if confl := s.propagateWatches(p, s.watches); confl != ClaRefUndef {
+			c.qhead, c.coreHead = len(s.trail), len(s.trail)
+			return confl
+		}
+	}
+}
+
+//ReadProof reads a DRAT proof in the textual or the binary format
+//The format is detected from the first bytes of the proof
+func (c *DratChecker) ReadProof(r io.Reader) error {
+	in := bufio.NewReaderSize(r, 1024*1024)
+	head, _ := in.Peek(64)
+	if isBinaryProof(head) {
+		return c.readBinaryProof(in)
+	}
+	return c.readTextProof(in)
+}
+
+//isBinaryProof returns true if the bytes contain a character which a textual proof doesn't have
+func isBinaryProof(head []byte) bool {
+	for _, b := range head {
+		if !bytes.ContainsRune([]byte("0123456789-d \t\r\nc"), rune(b)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *DratChecker) readTextProof(in *bufio.Reader) error {
+	lineNumber := 0
+	for {
+		line, err := in.ReadBytes('\n')
+		if len(line) == 0 && err == io.EOF {
+			return nil
+		}
+		if err != nil && err != io.EOF {
+			return err
+		}
+		lineNumber++
+		fields := bytes.Fields(line)
+		if len(fields) == 0 || fields[0][0] == 'c' {
+			continue
+		}
+		deletion := string(fields[0]) == "d"
+		if deletion {
+			fields = fields[1:]
+		}
+		if len(fields) == 0 || string(fields[len(fields)-1]) != "0" {
+			return fmt.Errorf("The proof line %d is not terminated by 0: %s", lineNumber, bytes.TrimSpace(line))
+		}
+		lits := make([]Lit, 0, len(fields)-1)
+		for _, f := range fields[:len(fields)-1] {
+			x, err := strconv.Atoi(string(f))
+			if err != nil || x == 0 {
+				return fmt.Errorf("The proof line %d has a wrong literal: %s", lineNumber, f)
+			}
+			lits = append(lits, *NewLitFromDimacs(x))
+		}
+		c.steps = append(c.steps, dratStep{deletion: deletion, lits: lits})
+	}
+}
+
+func (c *DratChecker) readBinaryProof(in *bufio.Reader) error {
+	for step := 1; ; step++ {
+		kind, err := in.ReadByte()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if kind != 'a' && kind != 'd' {
+			return fmt.Errorf("The binary proof has a wrong step %d: %#x", step, kind)
+		}
+		lits, err := readBinaryLits(in)
+		if err == io.EOF {
+			return fmt.Errorf("The binary proof is truncated at the step %d", step)
+		}
+		if err != nil {
+			return fmt.Errorf("The binary proof has a wrong literal at the step %d: %v", step, err)
+		}
+		c.steps = append(c.steps, dratStep{deletion: kind == 'd', lits: lits})
+	}
+}
+
+//readBinaryLits reads the literals written by appendBinaryLits
+func readBinaryLits(in io.ByteReader) ([]Lit, error) {
+	var lits []Lit
+	for {
+		var u uint64
+		for shift := uint(0); ; shift += 7 {
+			b, err := in.ReadByte()
+			if err != nil {
+				return nil, err
+			}
+			u |= uint64(b&0x7f) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		if u == 0 {
+			return lits, nil
+		}
+		if u == 1 {
+			return nil, fmt.Errorf("The binary proof has the variable 0")
+		}
+		x := int(u >> 1)
+		if u&1 == 1 {
+			x = -x
+		}
+		lits = append(lits, *NewLitFromDimacs(x))
+	}
+}
+
+//clauseKey returns a key of the set of the literals
+func clauseKey(lits []Lit) string {
+	xs := make([]int, len(lits))
+	for i, p := range lits {
+		xs[i] = p.X
+	}
+	sort.Ints(xs)
+	var buf []byte
+	for i, x := range xs {
+		if i > 0 && x == xs[i-1] {
+			continue
+		}
+		buf = strconv.AppendInt(buf, int64(x), 10)
+		buf = append(buf, ' ')
+	}
+	return string(buf)
+}
+
+//Check verifies the proof read by ReadProof
+//It returns nil if the proof shows that the formula is unsatisfiable
+func (c *DratChecker) Check() error {
+	if c.checked {
+		return fmt.Errorf("The proof is already checked")
+	}
+	c.checked = true
+	if err := c.forward(); err != nil {
+		return err
+	}
+	return c.backward()
+}
+
+//forward adds the clauses and the lemmas until the top-level propagation finds a conflict
+func (c *DratChecker) forward() error {
+	clauses := map[string][]ClauseReference{}
+	for cr := ClauseReference(0); int(cr) < c.numClauses; cr++ {
+		cla := c.s.claAllocator.GetClause(cr)
+		clauses[clauseKey(cla.Data)] = append(clauses[clauseKey(cla.Data)], cr)
+		if c.conflict == ClaRefUndef {
+			c.conflict = c.attach(cr)
+		}
+	}
+	if c.conflict == ClaRefUndef {
+		c.conflict = c.propagate()
+	}
+	for i := range c.steps {
+		if c.conflict != ClaRefUndef {
+			c.steps = c.steps[:i]
+			break
+		}
+		step := &c.steps[i]
+		step.trailLen = len(c.s.trail)
+		if step.deletion {
+			key := clauseKey(step.lits)
+			refs := clauses[key]
+			step.claRef = ClaRefUndef
+			if len(refs) == 0 {
+				c.NumIgnoredDeletion++
+				continue
+			}
+			cr := refs[len(refs)-1]
+			cla := c.s.claAllocator.GetClause(cr)
+			//The deletion of a unit clause or a reason is ignored as drat-trim does
+			if first := cla.At(0); cla.Size() <= 1 || c.reason(first.Var()) == cr {
+				c.NumIgnoredDeletion++
+				continue
+			}
+			clauses[key] = refs[:len(refs)-1]
+			c.detach(cr)
+			step.claRef = cr
+			continue
+		}
+		c.NumLemmas++
+		if len(step.lits) == 0 {
+			return fmt.Errorf("The empty clause at the step %d is not implied by unit propagation", i+1)
+		}
+		step.pivot = step.lits[0]
+		step.claRef = c.newClause(step.lits)
+		cla := c.s.claAllocator.GetClause(step.claRef)
+		clauses[clauseKey(cla.Data)] = append(clauses[clauseKey(cla.Data)], step.claRef)
+		if c.conflict = c.attach(step.claRef); c.conflict == ClaRefUndef {
+			c.conflict = c.propagate()
+		}
+	}
+	if c.conflict == ClaRefUndef {
+		return fmt.Errorf("The proof doesn't derive a conflict by unit propagation")
+	}
+	return nil
+}
+
+//backward verifies the core lemmas from the last one to the first one
+func (c *DratChecker) backward() error {
+	c.finalRUP = c.analyze(c.conflict)
+	for i := len(c.steps) - 1; i >= 0; i-- {
+		step := &c.steps[i]
+		if step.claRef == ClaRefUndef {
+			continue
+		}
+		c.backtrack(step.trailLen)
+		c.qhead, c.coreHead = len(c.s.trail), len(c.s.trail)
+		if step.deletion {
+			if confl := c.attach(step.claRef); confl != ClaRefUndef {
+				panic(fmt.Errorf("The restored clause is falsified: %d", step.claRef))
+			}
+			continue
+		}
+		c.detach(step.claRef)
+		if !c.core[step.claRef] {
+			continue
+		}
+		c.NumCoreLemmas++
+		hints, err := c.checkLemma(step)
+		if err != nil {
+			return fmt.Errorf("The lemma %d (%s) is not RUP nor RAT: %v", i+1, dimacsString(step.lits), err)
+		}
+		c.hints[step.claRef] = hints
+	}
+	return nil
+}
+
+//checkLemma checks whether the lemma is RUP or RAT for the pivot
+func (c *DratChecker) checkLemma(step *dratStep) (*dratHints, error) {
+	if rup, ok := c.checkRUP(step.lits); ok {
+		return &dratHints{rup: rup}, nil
+	}
+	hints := &dratHints{}
+	pivot := step.pivot
+	for cr := ClauseReference(0); int(cr) < len(c.active); cr++ {
+		if !c.active[cr] {
+			continue
+		}
+		cla := c.s.claAllocator.GetClause(cr)
+		hasNegatedPivot := false
+		for i := 0; i < cla.Size(); i++ {
+			if cla.At(i) == pivot.Flip() {
+				hasNegatedPivot = true
+			}
+		}
+		if !hasNegatedPivot {
+			continue
+		}
+		//The resolvent on the pivot
+		resolvent := append([]Lit(nil), step.lits...)
+		tautology := false
+		for i := 0; i < cla.Size(); i++ {
+			q := cla.At(i)
+			if q == pivot.Flip() {
+				continue
+			}
+			for _, p := range step.lits {
+				if p == q.Flip() {
+					tautology = true
+				}
+			}
+			resolvent = append(resolvent, q)
+		}
+		c.markCore(cr)
+		if tautology {
+			hints.rat = append(hints.rat, dratResolvent{candidate: cr})
+			continue
+		}
+		rup, ok := c.checkRUP(resolvent)
+		if !ok {
+			return nil, fmt.Errorf("the resolvent with the clause %s is not implied by unit propagation", dimacsString(cla.Data[:cla.Size()]))
+		}
+		hints.rat = append(hints.rat, dratResolvent{candidate: cr, rup: rup})
+	}
+	c.NumRATLemmas++
+	return hints, nil
+}
+
+//checkRUP assigns the negation of the literals and propagates
+//It returns the clauses used to derive the conflict if it finds a conflict
+func (c *DratChecker) checkRUP(lits []Lit) ([]ClauseReference, bool) {
+	base := len(c.s.trail)
+	defer func() {
+		c.backtrack(base)
+		c.qhead, c.coreHead = base, base
+	}()
+	confl := ClaRefUndef
+	for _, p := range lits {
+		if c.s.valueLit(p) == LitBoolUndef {
+			c.s.uncheckedEnqueue(p.Flip(), ClaRefUndef)
+		} else if c.s.valueLit(p) == LitBoolTrue {
+			if c.reason(p.Var()) == ClaRefUndef {
+				//The lemma is a tautology
+				return nil, true
+			}
+			//The negation of p conflicts with the reason of p
+			confl = c.reason(p.Var())
+			break
+		}
+	}
+	if confl == ClaRefUndef {
+		confl = c.propagate()
+	}
+	if confl == ClaRefUndef {
+		return nil, false
+	}
+	return c.analyze(confl), true
+}
+
+//analyze marks the clauses used to derive the conflict as core
+//and returns them in the order of the trail followed by the conflicting clause
+func (c *DratChecker) analyze(confl ClauseReference) []ClauseReference {
+	cla := c.s.claAllocator.GetClause(confl)
+	c.markCore(confl)
+	for i := 0; i < cla.Size(); i++ {
+		c.s.seen[cla.Data[i].Var()] = true
+	}
+	var used []ClauseReference
+	for i := len(c.s.trail) - 1; i >= 0; i-- {
+		x := c.s.trail[i].Var()
+		if !c.s.seen[x] {
+			continue
+		}
+		c.s.seen[x] = false
+		reason := c.reason(x)
+		if reason == ClaRefUndef || reason == confl {
+			continue
+		}
+		c.markCore(reason)
+		used = append(used, reason)
+		r := c.s.claAllocator.GetClause(reason)
+		for k := 0; k < r.Size(); k++ {
+			c.s.seen[r.Data[k].Var()] = true
+		}
+	}
+	for i := 0; i < cla.Size(); i++ {
+		c.s.seen[cla.Data[i].Var()] = false
+	}
+	for i, j := 0, len(used)-1; i < j; i, j = i+1, j-1 {
+		used[i], used[j] = used[j], used[i]
+	}
+	return append(used, confl)
+}
+
+//WriteCore writes the clauses of the formula used by the proof in DIMACS CNF format
+func (c *DratChecker) WriteCore(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	numCore := 0
+	for cr := 0; cr < c.numClauses; cr++ {
+		if c.core[cr] {
+			numCore++
+		}
+	}
+	fmt.Fprintf(out, "p cnf %d %d\n", c.s.NumVars(), numCore)
+	for cr := 0; cr < c.numClauses; cr++ {
+		if c.core[cr] {
+			cla := c.s.claAllocator.GetClause(ClauseReference(cr))
+			fmt.Fprintf(out, "%s\n", dimacsString(cla.Data[:cla.Size()]))
+		}
+	}
+	return out.Flush()
+}
+
+//WriteLRAT writes the verified proof in LRAT format
+//The clauses of the formula have the IDs 1, 2, ... in the order of the formula and the core lemmas follow them
+func (c *DratChecker) WriteLRAT(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	ids := map[ClauseReference]uint64{}
+	for cr := 0; cr < c.numClauses; cr++ {
+		ids[ClauseReference(cr)] = uint64(cr + 1)
+	}
+	nextID := uint64(c.numClauses)
+	writeLemma := func(lits []Lit, hints *dratHints) {
+		nextID++
+		buf := strconv.AppendUint(nil, nextID, 10)
+		buf = append(buf, ' ')
+		buf = appendTextLits(buf, lits)
+		for _, cr := range hints.rup {
+			buf = strconv.AppendUint(append(buf, ' '), ids[cr], 10)
+		}
+		for _, resolvent := range hints.rat {
+			buf = strconv.AppendInt(append(buf, ' '), -int64(ids[resolvent.candidate]), 10)
+			for _, cr := range resolvent.rup {
+				buf = strconv.AppendUint(append(buf, ' '), ids[cr], 10)
+			}
+		}
+		out.Write(append(buf, " 0\n"...))
+	}
+	for i := range c.steps {
+		step := &c.steps[i]
+		hints, ok := c.hints[step.claRef]
+		if step.deletion || !ok {
+			continue
+		}
+		//The pivot of RAT must be the first literal
+		lits := []Lit{step.pivot}
+		cla := c.s.claAllocator.GetClause(step.claRef)
+		for k := 0; k < cla.Size(); k++ {
+			if cla.At(k) != step.pivot {
+				lits = append(lits, cla.At(k))
+			}
+		}
+		writeLemma(lits, hints)
+		ids[step.claRef] = nextID
+	}
+	writeLemma(nil, &dratHints{rup: c.finalRUP})
+	return out.Flush()
+}
+
+//dimacsString returns the literals in DIMACS format terminated by 0
+func dimacsString(lits []Lit) string {
+	return string(appendTextLits(nil, lits))
+}
@@ -0,0 +1,115 @@
+package gatosat
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/togatoga/gatosat/lrat"
+)
+
+//pigeonHoleProof returns the clauses of the pigeonhole problem and a proof of its unsatisfiability
+func pigeonHoleProof(t *testing.T, pigeons, holes int, format ProofFormat) (int, [][]Lit, []byte) {
+	var proof bytes.Buffer
+	s := NewSolver()
+	s.SetProof(&proof, format)
+	pigeonHole(s, pigeons, holes)
+	if status := s.Solve(); status != LitBoolFalse {
+		t.Fatalf("The solver returns a wrong value: %v", status)
+	}
+	s.FlushProof()
+
+	r := NewSolver()
+	var cnf strings.Builder
+	pigeonHole(r, pigeons, holes)
+	cnf.WriteString("p cnf 0 0\n")
+	for _, cr := range r.clauses {
+		c := r.claAllocator.GetClause(cr)
+		cnf.WriteString(dimacsString(c.Data[:c.Size()]) + "\n")
+	}
+	numVars, clauses, err := ReadCNF(strings.NewReader(cnf.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return numVars, clauses, proof.Bytes()
+}
+
+func TestDratChecker(t *testing.T) {
+	for _, format := range []ProofFormat{ProofDRAT, ProofBinaryDRAT} {
+		numVars, clauses, proof := pigeonHoleProof(t, 6, 5, format)
+		checker := NewDratChecker(numVars, clauses)
+		if err := checker.ReadProof(bytes.NewReader(proof)); err != nil {
+			t.Fatal(err)
+		}
+		if err := checker.Check(); err != nil {
+			t.Fatalf("The proof is not verified: %v", err)
+		}
+		if checker.NumCoreLemmas == 0 || checker.NumCoreLemmas > checker.NumLemmas {
+			t.Fatalf("The number of the core lemmas is wrong: %d / %d", checker.NumCoreLemmas, checker.NumLemmas)
+		}
+
+		//The core is unsatisfiable
+		var core bytes.Buffer
+		if err := checker.WriteCore(&core); err != nil {
+			t.Fatal(err)
+		}
+		s := NewSolver()
+		if err := ParseDimacs(bufio.NewScanner(&core), s); err != nil {
+			t.Fatal(err)
+		}
+		if status := s.Solve(); status != LitBoolFalse {
+			t.Fatalf("The core is satisfiable")
+		}
+
+		var proofLRAT bytes.Buffer
+		if err := checker.WriteLRAT(&proofLRAT); err != nil {
+			t.Fatal(err)
+		}
+		if err := lrat.NewChecker(dimacsClauses(clauses)).Check(&proofLRAT); err != nil {
+			t.Fatalf("The LRAT proof is not verified: %v", err)
+		}
+	}
+
+	//A proof without the first half of the lemmas is not verified
+	numVars, clauses, proof := pigeonHoleProof(t, 6, 5, ProofDRAT)
+	lines := strings.SplitAfter(string(proof), "\n")
+	checker := NewDratChecker(numVars, clauses)
+	checker.ReadProof(strings.NewReader(strings.Join(lines[len(lines)/2:], "")))
+	if err := checker.Check(); err == nil {
+		t.Fatalf("The broken proof is verified")
+	}
+}
+
+func TestDratCheckerRAT(t *testing.T) {
+	//(1 -2) is not RUP but RAT on 1 because the resolvent with (-1 2) is a tautology
+	clauses := [][]Lit{
+		{*NewLitFromDimacs(-1), *NewLitFromDimacs(2)},
+		{*NewLitFromDimacs(3), *NewLitFromDimacs(4)},
+	}
+	checker := NewDratChecker(4, clauses)
+	checker.ReadProof(strings.NewReader("1 -2 0\n-2 3 0\n"))
+	if err := checker.forward(); err == nil {
+		t.Fatalf("The proof without a conflict is verified")
+	}
+	checker.backtrack(0)
+	checker.detach(checker.steps[1].claRef)
+	checker.detach(checker.steps[0].claRef)
+	hints, err := checker.checkLemma(&checker.steps[0])
+	if err != nil || len(hints.rat) != 1 {
+		t.Fatalf("The RAT lemma is not verified: %v", err)
+	}
+	if _, err := checker.checkLemma(&checker.steps[1]); err == nil {
+		t.Fatalf("The lemma which is neither RUP nor RAT is verified")
+	}
+}
+
+func TestDratCheckerWrongProof(t *testing.T) {
+	clauses := [][]Lit{{*NewLitFromDimacs(1)}, {*NewLitFromDimacs(-1)}}
+	for _, proof := range []string{"1 2\n", "1 x 0\n", "a\x02", "a\x01\x00", "d\x04\x01\x00"} {
+		checker := NewDratChecker(2, clauses)
+		if err := checker.ReadProof(strings.NewReader(proof)); err == nil {
+			t.Fatalf("The wrong proof is read: %q", proof)
+		}
+	}
+}
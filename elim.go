@@ -0,0 +1,341 @@
+package gatosat
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	//elimClauseLimit is the maximum size of a resolvent added by the variable elimination
+	elimClauseLimit = 20
+	//elimOccurrenceLimit is the maximum number of the clauses of a variable tried by the variable elimination
+	elimOccurrenceLimit = 400
+)
+
+//elimEntry is a clause removed by the variable elimination, the substitution or the blocked and covered clause elimination
+//The model is extended by making the witness true if the clause is not satisfied, from the last entry to the first
+type elimEntry struct {
+	witness Lit    //The literal of the eliminated variable or the literal on which the clause is blocked
+	lits    []Lit  //The clause. nil for the default value of the eliminated variable, which is the literal in more clauses
+	id      uint64 //The ID of the clause if it is removed
+	removed bool   //The clause is removed from the problem and added back by the restoration. false for the default value and the clauses extended by the covered clause elimination
+}
+
+//SetFrozen declares whether the variable must be kept by the variable elimination and the equivalent literal substitution
+//The variables of the assumptions, the scopes and the external propagator are always kept
+func (s *Solver) SetFrozen(x Var, frozen bool) {
+	s.frozen[int(x)] = frozen
+}
+
+//IsEliminated returns true if the variable is eliminated from the problem or substituted by an equivalent literal
+//Its value in the model is given by the clauses removed with it
+func (s *Solver) IsEliminated(x Var) bool {
+	return s.eliminated[int(x)]
+}
+
+//eliminate runs the bounded variable elimination on the problem clauses once before the first search
+//A variable is eliminated by replacing its clauses with their resolvents if the resolvents are not more than the clauses.
+//Only the resolvents between the clauses of a gate defining the variable and the other clauses are needed if it has a gate
+//It returns false if the problem is unsatisfiable
+func (s *Solver) eliminate() bool {
+	if !s.opts.Elimination || s.elimDone || len(s.scopes) > 0 || s.propagator != nil {
+		return s.ok
+	}
+	s.elimDone = true
+	occ := make([][]ClauseReference, 2*s.NumVars())
+	for _, cr := range s.clauses {
+		s.addOccurrences(occ, cr)
+	}
+	var candidates []Var
+	for v, removable := range s.removableVars() {
+		if removable {
+			candidates = append(candidates, Var(v))
+		}
+	}
+	//The variables with the fewest resolvents first
+	cost := func(x Var) int {
+		return len(occ[NewLit(x, false).X]) * len(occ[NewLit(x, true).X])
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return cost(candidates[i]) < cost(candidates[j])
+	})
+	for _, x := range candidates {
+		if s.valueVar(x) != LitBoolUndef {
+			continue
+		}
+		s.eliminateVar(x, occ)
+		if !s.ok {
+			break
+		}
+	}
+
+	//The learnt clauses of the eliminated variables are removed and the removed problem clauses are dropped from the list
+	j := 0
+	for _, cr := range s.learnts {
+		c := s.claAllocator.GetClause(cr)
+		removed := false
+		for i := 0; i < c.Size() && !removed; i++ {
+			q := c.At(i)
+			removed = s.eliminated[q.Var()]
+		}
+		if removed {
+			s.removeClause(cr)
+		} else {
+			s.learnts[j] = cr
+			j++
+		}
+	}
+	s.learnts = s.learnts[:j]
+	j = 0
+	for _, cr := range s.clauses {
+		if !s.claAllocator.Clauses[cr].IsRemoved() {
+			s.clauses[j] = cr
+			j++
+		}
+	}
+	s.clauses = s.clauses[:j]
+	return s.ok
+}
+
+//removableVars returns the variables which may be removed from the problem by the variable elimination or the substitution
+//The frozen variables, the variables of the assumptions, the scopes and the external propagator and the non-decision variables are kept
+func (s *Solver) removableVars() []bool {
+	removable := make([]bool, s.NumVars())
+	for v := range removable {
+		x := Var(v)
+		removable[v] = !s.frozen[x] && s.decision[x] && !s.activation[x] && !s.observed[x] && !s.eliminated[x] && s.valueVar(x) == LitBoolUndef
+	}
+	for _, p := range s.assumptions {
+		removable[p.Var()] = false
+	}
+	return removable
+}
+
+func (s *Solver) addOccurrences(occ [][]ClauseReference, cr ClauseReference) {
+	c := s.claAllocator.GetClause(cr)
+	for i := 0; i < c.Size(); i++ {
+		occ[c.At(i).X] = append(occ[c.At(i).X], cr)
+	}
+}
+
+//liveOccurrences returns the clauses containing p which are not removed
+//The clauses satisfied at level 0 are removed
+func (s *Solver) liveOccurrences(occ [][]ClauseReference, p Lit) []ClauseReference {
+	j := 0
+	list := occ[p.X]
+	for _, cr := range list {
+		if s.claAllocator.Clauses[cr].IsRemoved() {
+			continue
+		}
+		if s.satisfied(s.claAllocator.GetClause(cr)) {
+			s.removeClause(cr)
+			continue
+		}
+		list[j] = cr
+		j++
+	}
+	occ[p.X] = list[:j]
+	return occ[p.X]
+}
+
+//eliminateVar eliminates the variable if the number of the resolvents is within the bound
+func (s *Solver) eliminateVar(x Var, occ [][]ClauseReference) {
+	posLit, negLit := *NewLit(x, false), *NewLit(x, true)
+	pos, neg := s.liveOccurrences(occ, posLit), s.liveOccurrences(occ, negLit)
+	if len(pos)+len(neg) > elimOccurrenceLimit {
+		return
+	}
+	gatePos, gateNeg, gate := s.findGate(posLit, pos, neg)
+	if !gate {
+		gateNegOfNeg, gatePosOfNeg, gateOfNeg := s.findGate(negLit, neg, pos)
+		gatePos, gateNeg, gate = gatePosOfNeg, gateNegOfNeg, gateOfNeg
+	}
+
+	type resolvent struct {
+		lits  []Lit
+		hints []uint64
+	}
+	var resolvents []resolvent
+	for i, pcr := range pos {
+		for j, ncr := range neg {
+			if gate && gatePos[i] == gateNeg[j] {
+				//The resolvents of two gate clauses are tautologies and the ones of two other clauses are implied
+				continue
+			}
+			c, d := s.claAllocator.GetClause(pcr), s.claAllocator.GetClause(ncr)
+			lits, ok := resolve(c, d, x)
+			if !ok {
+				continue
+			}
+			if len(lits) > elimClauseLimit || len(resolvents) >= len(pos)+len(neg) {
+				return
+			}
+			resolvents = append(resolvents, resolvent{lits: lits, hints: []uint64{c.id, d.id}})
+		}
+	}
+
+	//The removed clauses stay in the proof, so they can be added back with the same IDs
+	for _, side := range []struct {
+		witness Lit
+		list    []ClauseReference
+	}{{posLit, pos}, {negLit, neg}} {
+		for _, cr := range side.list {
+			c := s.claAllocator.GetClause(cr)
+			s.elimStack = append(s.elimStack, elimEntry{witness: side.witness, lits: append([]Lit(nil), c.Data[:c.Size()]...), id: c.id, removed: true})
+			s.deleteClause(cr)
+		}
+	}
+	s.elimStack = append(s.elimStack, elimEntry{witness: *NewLit(x, len(pos) <= len(neg)), lits: nil})
+	s.eliminated[x] = true
+	s.SetDecisionVar(x, false)
+	s.statistics.EliminatedVarCount++
+
+	for _, r := range resolvents {
+		id := s.newClauseID()
+		if s.proof != nil {
+			s.proof.add(id, r.lits, r.hints)
+		}
+		numClauses := len(s.clauses)
+		if !s.addClauseWithID(id, r.lits) {
+			return
+		}
+		if len(s.clauses) > numClauses {
+			s.addOccurrences(occ, s.clauses[len(s.clauses)-1])
+		}
+	}
+}
+
+//findGate finds the clauses defining p as an AND gate of other literals in the clauses of p and ~p
+//p = a_1 and ... and a_k is defined by the clauses (~p | a_i) and (p | ~a_1 | ... | ~a_k)
+//It returns the gate clauses of both lists
+func (s *Solver) findGate(p Lit, pos, neg []ClauseReference) ([]bool, []bool, bool) {
+	//binary[a] is the index of the clause (~p | a) in neg
+	binary := map[Lit]int{}
+	for j, cr := range neg {
+		c := s.claAllocator.GetClause(cr)
+		if c.Size() != 2 {
+			continue
+		}
+		a := c.At(0)
+		if a.Var() == p.Var() {
+			a = c.At(1)
+		}
+		binary[a] = j
+	}
+	if len(binary) == 0 {
+		return nil, nil, false
+	}
+	for i, cr := range pos {
+		c := s.claAllocator.GetClause(cr)
+		defined := true
+		for k := 0; k < c.Size() && defined; k++ {
+			q := c.At(k)
+			if q.Var() != p.Var() {
+				_, defined = binary[q.Flip()]
+			}
+		}
+		if !defined {
+			continue
+		}
+		gatePos, gateNeg := make([]bool, len(pos)), make([]bool, len(neg))
+		gatePos[i] = true
+		for k := 0; k < c.Size(); k++ {
+			if q := c.At(k); q.Var() != p.Var() {
+				gateNeg[binary[q.Flip()]] = true
+			}
+		}
+		return gatePos, gateNeg, true
+	}
+	return nil, nil, false
+}
+
+//resolve returns the resolvent of c and d on x, or false if it is a tautology
+func resolve(c, d *Clause, x Var) ([]Lit, bool) {
+	lits := make([]Lit, 0, c.Size()+d.Size()-2)
+	for i := 0; i < c.Size(); i++ {
+		if q := c.At(i); q.Var() != x {
+			lits = append(lits, q)
+		}
+	}
+	n := len(lits)
+	for i := 0; i < d.Size(); i++ {
+		q := d.At(i)
+		if q.Var() == x {
+			continue
+		}
+		duplicate := false
+		for _, r := range lits[:n] {
+			if r == q.Flip() {
+				return nil, false
+			}
+			duplicate = duplicate || r == q
+		}
+		if !duplicate {
+			lits = append(lits, q)
+		}
+	}
+	return lits, true
+}
+
+//extendModel gives the values of the eliminated variables in the model
+func (s *Solver) extendModel() {
+	isTrue := func(p Lit) bool {
+		return s.model[p.Var()] == LitBoolTrue && !p.Sign() || s.model[p.Var()] == LitBoolFalse && p.Sign()
+	}
+	for i := len(s.elimStack) - 1; i >= 0; i-- {
+		e := s.elimStack[i]
+		if e.lits == nil && s.userPolarity[e.witness.Var()] != LitBoolUndef {
+			//The default value of the eliminated variable follows the polarity given by SetPolarity
+			e.witness = *NewLit(e.witness.Var(), s.userPolarity[e.witness.Var()] == LitBoolFalse)
+		}
+		satisfied := false
+		for _, q := range e.lits {
+			if isTrue(q) {
+				satisfied = true
+				break
+			}
+		}
+		if !satisfied {
+			s.model[e.witness.Var()] = LitBoolTrue
+			if e.witness.Sign() {
+				s.model[e.witness.Var()] = LitBoolFalse
+			}
+		}
+	}
+}
+
+//restoreEliminated adds the clauses removed by the variable elimination, the equivalences of the substituted variables
+//and the blocked and covered clauses back to the problem if the literals contain an eliminated variable or a witness variable,
+//whose value may be flipped by the extension of the model
+//Every eliminated variable is restored, since the removed clauses of a variable may contain the variables eliminated after it
+//The elimination isn't run again after the restoration
+func (s *Solver) restoreEliminated(lits []Lit) {
+	found := false
+	for _, p := range lits {
+		if int(p.Var()) >= s.NumVars() {
+			panic(fmt.Errorf("The literal is not a variable of the solver: %d", p.Var()))
+		}
+		found = found || s.eliminated[p.Var()] || s.witness[p.Var()]
+	}
+	if found {
+		s.restoreAll()
+	}
+}
+
+//restoreAll adds every clause of the elimination stack back to the problem
+func (s *Solver) restoreAll() {
+	stack := s.elimStack
+	s.elimStack = nil
+	for v, eliminated := range s.eliminated {
+		if eliminated {
+			s.eliminated[v] = false
+			s.SetDecisionVar(Var(v), true)
+		}
+		s.witness[v] = false
+	}
+	for _, e := range stack {
+		if e.removed {
+			s.addClauseWithID(e.id, append([]Lit(nil), e.lits...))
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package gatosat
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/togatoga/gatosat/lrat"
+)
+
+//randomCircuit returns the Tseitin encoding of random AND gates over the inputs and some random clauses
+func randomCircuit(rnd *rand.Rand, numInputs, numGates, numClauses int) [][]Lit {
+	var clauses [][]Lit
+	for g := numInputs; g < numInputs+numGates; g++ {
+		x := *NewLit(Var(g), false)
+		a := *NewLit(Var(rnd.Intn(g)), rnd.Intn(2) == 0)
+		b := *NewLit(Var(rnd.Intn(g)), rnd.Intn(2) == 0)
+		clauses = append(clauses, []Lit{x.Flip(), a}, []Lit{x.Flip(), b}, []Lit{x, a.Flip(), b.Flip()})
+	}
+	return append(clauses, randomClauses(rnd, numInputs+numGates, numClauses)...)
+}
+
+//newEliminationSolver returns a solver which runs the bounded variable elimination
+func newEliminationSolver() *Solver {
+	opts := DefaultOptions()
+	opts.Elimination = true
+	s, err := NewSolverWithOptions(opts)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func TestEliminate(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	numVars := 14
+	eliminated := uint64(0)
+	for i := 0; i < 300; i++ {
+		clauses := randomCircuit(rnd, 6, 8, 1+rnd.Intn(6))
+		var proof bytes.Buffer
+		s := newEliminationSolver()
+		s.SetClauseStore(NewMemoryClauseStore())
+		s.SetProof(&proof, ProofLRAT)
+		for v := 0; v < numVars; v++ {
+			s.NewVar()
+		}
+		for _, c := range clauses {
+			s.AddClause(c)
+		}
+		status := s.Solve()
+		if expected := bruteForce(numVars, clauses, nil); status != expected {
+			t.Fatalf("The solver returns a wrong value: %v (expected %v) clauses %v", status, expected, clauses)
+		}
+		if status == LitBoolTrue {
+			if err := s.VerifyModel(); err != nil {
+				t.Fatalf("The extended model is wrong: %v", err)
+			}
+		} else {
+			//The resolvents are derived from the removed clauses
+			s.FlushProof()
+			if err := lrat.NewChecker(dimacsClauses(clauses)).Check(&proof); err != nil {
+				t.Fatalf("The LRAT proof is not verified: %v", err)
+			}
+		}
+		if err := s.CheckInvariants(); err != nil {
+			t.Fatal(err)
+		}
+		eliminated += s.Statistics().EliminatedVarCount
+	}
+	if eliminated == 0 {
+		t.Fatalf("No variable is eliminated")
+	}
+}
+
+func TestEliminateRestore(t *testing.T) {
+	rnd := rand.New(rand.NewSource(2))
+	numVars := 14
+	for i := 0; i < 200; i++ {
+		clauses := randomCircuit(rnd, 6, 8, rnd.Intn(3))
+		s := newEliminationSolver()
+		for v := 0; v < numVars; v++ {
+			s.NewVar()
+		}
+		for _, c := range clauses {
+			s.AddClause(c)
+		}
+		s.Solve()
+		//The clauses and the assumptions on the eliminated variables bring their clauses back
+		var x Var
+		for x = 0; int(x) < numVars && !s.IsEliminated(x); x++ {
+		}
+		if int(x) == numVars {
+			continue
+		}
+		assumptions := []Lit{*NewLit(x, rnd.Intn(2) == 0)}
+		status := s.SolveWithAssumptions(assumptions)
+		if expected := bruteForce(numVars, clauses, assumptions); status != expected {
+			t.Fatalf("The solver returns a wrong value with the assumption: %v (expected %v)", status, expected)
+		}
+		if status == LitBoolTrue {
+			checkModel(t, s.Model(), clauses, assumptions)
+		}
+		c := []Lit{*NewLit(x, rnd.Intn(2) == 0), *NewLit(Var(rnd.Intn(numVars)), rnd.Intn(2) == 0)}
+		clauses = append(clauses, c)
+		s.AddClause(c)
+		status = s.Solve()
+		if expected := bruteForce(numVars, clauses, nil); status != expected {
+			t.Fatalf("The solver returns a wrong value after adding a clause: %v (expected %v)", status, expected)
+		}
+		if status == LitBoolTrue {
+			checkModel(t, s.Model(), clauses, nil)
+		}
+	}
+}
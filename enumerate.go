@@ -0,0 +1,117 @@
+package gatosat
+
+import "fmt"
+
+//EnumerateModels calls fn for every model of the problem projected to the variables of projection
+//Each model is reported once as the list of the projected literals. All variables except the
+//activation variables of the scopes are projected if projection is empty
+//The enumeration stops when fn returns false or limit models are found (limit <= 0 means no limit)
+//It returns the number of the models and LitBoolFalse if all models are enumerated
+//or LitBoolUndef if it is stopped by fn, the limit, a budget or an interrupt
+//The blocking clauses are added inside a scope, so they are retracted before it returns
+func (s *Solver) EnumerateModels(projection []Var, limit int, fn func(model []Lit) bool) (int, LitBool) {
+	return s.enumerate(projection, limit, false, fn)
+}
+
+//EnumerateCubes is the same as EnumerateModels but generalizes every model to a partial assignment
+//of the projected variables (a cube) before blocking it
+//Every assignment of the projected variables which agrees with a cube can be extended to a model,
+//so a cube can cover many models. The projected variables which are not in a cube are don't cares
+func (s *Solver) EnumerateCubes(projection []Var, limit int, fn func(cube []Lit) bool) (int, LitBool) {
+	return s.enumerate(projection, limit, true, fn)
+}
+
+func (s *Solver) enumerate(projection []Var, limit int, generalize bool, fn func([]Lit) bool) (int, LitBool) {
+	if len(projection) == 0 {
+		for v := 0; v < s.NumVars(); v++ {
+			if !s.activation[v] {
+				projection = append(projection, Var(v))
+			}
+		}
+	}
+	projected := make([]bool, s.NumVars())
+	for _, v := range projection {
+		if int(v) >= s.NumVars() {
+			panic(fmt.Errorf("The projected variable is not a variable of the solver: %d", v))
+		}
+		projected[v] = true
+	}
+
+	//The implicants are computed on the clauses of the solver, so the clauses removed by the preprocessing are added back
+	s.restoreAll()
+	s.Push()
+	defer s.Pop()
+
+	count := 0
+	for limit <= 0 || count < limit {
+		status := s.Solve()
+		if status != LitBoolTrue {
+			return count, status
+		}
+		var cube []Lit
+		if generalize {
+			cube = s.generalizeModel(projection, projected)
+		} else {
+			cube = make([]Lit, 0, len(projection))
+			for _, v := range projection {
+				cube = append(cube, *NewLit(v, s.model[v] == LitBoolFalse))
+			}
+		}
+		count++
+		if !fn(cube) {
+			return count, LitBoolUndef
+		}
+		blocking := make([]Lit, len(cube))
+		for i, p := range cube {
+			blocking[i] = p.Flip()
+		}
+		if !s.AddClause(blocking) {
+			return count, LitBoolFalse
+		}
+	}
+	return count, LitBoolUndef
+}
+
+//generalizeModel returns the projected literals of an implicant of the problem clauses in the model
+//The implicant is found greedily preferring the literals of the variables which are not projected
+func (s *Solver) generalizeModel(projection []Var, projected []bool) []Lit {
+	required := make([]bool, s.NumVars())
+	isTrue := func(p Lit) bool {
+		return (s.model[p.Var()] == LitBoolTrue) != p.Sign()
+	}
+	//The assignments at level 0 are implied by the clauses which are already removed
+	for _, p := range s.trail {
+		required[p.Var()] = true
+	}
+	for _, cr := range s.clauses {
+		c := s.claAllocator.GetClause(cr)
+		candidate := Lit{X: LitUndef}
+		covered := false
+		for i := 0; i < c.Size(); i++ {
+			p := c.At(i)
+			if !isTrue(p) {
+				continue
+			}
+			if required[p.Var()] {
+				covered = true
+				break
+			}
+			if candidate.X == LitUndef || (projected[candidate.Var()] && !projected[p.Var()]) {
+				candidate = p
+			}
+		}
+		if !covered {
+			if candidate.X == LitUndef {
+				panic(fmt.Errorf("The clause is not satisfied by the model: %v", c.Data[:c.Size()]))
+			}
+			required[candidate.Var()] = true
+		}
+	}
+	var cube []Lit
+	for _, v := range projection {
+		if required[v] {
+			cube = append(cube, *NewLit(v, s.model[v] == LitBoolFalse))
+		}
+	}
+	return cube
+}
@@ -0,0 +1,184 @@
+package gatosat
+
+import (
+	"math/rand"
+	"testing"
+)
+
+//projectedModels returns the set of the assignments of the projection which can be extended to a model
+func projectedModels(numVars int, clauses [][]Lit, projection []Var) map[int]bool {
+	models := map[int]bool{}
+	for mask := 0; mask < 1<<uint(numVars); mask++ {
+		model := make([]LitBool, numVars)
+		for v := 0; v < numVars; v++ {
+			if mask&(1<<uint(v)) != 0 {
+				model[v] = LitBoolTrue
+			} else {
+				model[v] = LitBoolFalse
+			}
+		}
+		if satisfiedBy(model, clauses, nil) {
+			key := 0
+			for i, v := range projection {
+				if model[v] == LitBoolTrue {
+					key |= 1 << uint(i)
+				}
+			}
+			models[key] = true
+		}
+	}
+	return models
+}
+
+func TestEnumerateModels(t *testing.T) {
+	rnd := rand.New(rand.NewSource(2))
+	for iter := 0; iter < 100; iter++ {
+		numVars := 3 + rnd.Intn(5)
+		clauses := randomClauses(rnd, numVars, 1+rnd.Intn(2*numVars))
+		var projection []Var
+		for v := 0; v < numVars; v++ {
+			if rnd.Intn(2) == 0 {
+				projection = append(projection, Var(v))
+			}
+		}
+		if len(projection) == 0 {
+			projection = append(projection, Var(0))
+		}
+		expected := projectedModels(numVars, clauses, projection)
+
+		for _, generalize := range []bool{false, true} {
+			s := NewSolver()
+			for i := 0; i < numVars; i++ {
+				s.NewVar()
+			}
+			for _, c := range clauses {
+				s.AddClause(c)
+			}
+			found := map[int]bool{}
+			enumerate := s.EnumerateModels
+			if generalize {
+				enumerate = s.EnumerateCubes
+			}
+			_, status := enumerate(projection, 0, func(cube []Lit) bool {
+				//Expand the cube to all assignments of the projection
+				for mask := 0; mask < 1<<uint(len(projection)); mask++ {
+					agree := true
+					for _, p := range cube {
+						for i, v := range projection {
+							if v == p.Var() && (mask&(1<<uint(i)) != 0) == p.Sign() {
+								agree = false
+							}
+						}
+					}
+					if !agree {
+						continue
+					}
+					if found[mask] || !expected[mask] {
+						t.Fatalf("The cube %v is wrong: clauses %v projection %v", cube, clauses, projection)
+					}
+					found[mask] = true
+				}
+				return true
+			})
+			if status != LitBoolFalse || len(found) != len(expected) {
+				t.Fatalf("The models are not enumerated: %v %d %d clauses %v projection %v", status, len(found), len(expected), clauses, projection)
+			}
+			for _, cr := range append(s.clauses, s.learnts...) {
+				c := s.claAllocator.GetClause(cr)
+				for _, p := range c.Data[:c.Size()] {
+					if s.activation[p.Var()] {
+						t.Fatalf("The blocking clauses are not retracted: %v", c.Data[:c.Size()])
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestEnumerateModelsLimit(t *testing.T) {
+	s := NewSolver()
+	for i := 0; i < 4; i++ {
+		s.NewVar()
+	}
+	count, status := s.EnumerateModels(nil, 3, func(model []Lit) bool {
+		if len(model) != 4 {
+			t.Fatalf("The model is not projected to all variables: %v", model)
+		}
+		return true
+	})
+	if count != 3 || status != LitBoolUndef {
+		t.Fatalf("The limit is not respected: %d %v", count, status)
+	}
+	count, status = s.EnumerateModels(nil, 0, func(model []Lit) bool { return true })
+	if count != 16 || status != LitBoolFalse {
+		t.Fatalf("The models are not enumerated: %d %v", count, status)
+	}
+}
+
+func TestEnumerateCubesAfterPreprocessing(t *testing.T) {
+	rnd := rand.New(rand.NewSource(3))
+	example := [][]Lit{{{X: 0}, {X: 9}}, {{X: 0}, {X: 0}, {X: 3}}, {{X: 0}, {X: 3}, {X: 6}}, {{X: 2}, {X: 4}, {X: 4}}, {{X: 9}}, {{X: 5}, {X: 11}}, {{X: 4}}}
+	for iter := 0; iter < 100; iter++ {
+		numVars, clauses := 6, example
+		if iter > 0 {
+			numVars = 3 + rnd.Intn(5)
+			clauses = randomClauses(rnd, numVars, 1+rnd.Intn(2*numVars))
+		}
+		opts, err := PresetOptions("industrial")
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := NewSolverWithOptions(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < numVars; i++ {
+			s.NewVar()
+		}
+		for _, c := range clauses {
+			s.AddClause(c)
+		}
+		if s.Solve() != LitBoolTrue {
+			continue
+		}
+		checkCubes(t, s, numVars, clauses)
+	}
+}
+
+//checkCubes checks that the cubes enumerated by the solver are implicants of the clauses and cover all their models
+func checkCubes(t *testing.T, s *Solver, numVars int, clauses [][]Lit) {
+	count := 0
+	_, status := s.EnumerateCubes(nil, 0, func(cube []Lit) bool {
+		for mask := 0; mask < 1<<uint(numVars); mask++ {
+			model := make([]LitBool, numVars)
+			agree := true
+			for v := 0; v < numVars; v++ {
+				model[v] = LitBoolFalse
+				if mask&(1<<uint(v)) != 0 {
+					model[v] = LitBoolTrue
+				}
+			}
+			for _, p := range cube {
+				if (model[p.Var()] == LitBoolFalse) != p.Sign() {
+					agree = false
+				}
+			}
+			if !agree {
+				continue
+			}
+			if !satisfiedBy(model, clauses, nil) {
+				t.Fatalf("The cube %v is not an implicant: clauses %v", cube, clauses)
+			}
+			count++
+		}
+		return true
+	})
+	var projection []Var
+	for v := 0; v < numVars; v++ {
+		projection = append(projection, Var(v))
+	}
+	expected := len(projectedModels(numVars, clauses, projection))
+	if status != LitBoolFalse || count != expected {
+		t.Fatalf("The cubes don't cover the models: %v %d %d clauses %v", status, count, expected, clauses)
+	}
+}
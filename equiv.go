@@ -0,0 +1,261 @@
+package gatosat
+
+//numberedClause is a clause with its ID
+type numberedClause struct {
+	lits []Lit
+	id   uint64
+}
+
+//implication is an edge of the binary implication graph given by the binary clause of the ID
+type implication struct {
+	lit Lit
+	id  uint64
+}
+
+//substitute replaces the equivalent literals by a representative literal of each equivalence class in every clause
+//The equivalence classes are the strongly connected components of the binary implication graph found by Tarjan's algorithm.
+//The substituted variables are removed from the problem like the eliminated variables, and the binary clauses
+//(~l | r) and (l | ~r) between a literal l and its representative r are kept in the elimination stack to restore their values
+//It returns false if the problem is unsatisfiable
+func (s *Solver) substitute() bool {
+	if !s.opts.Substitution || len(s.scopes) > 0 || s.propagator != nil {
+		return s.ok
+	}
+	if !s.simplify() {
+		return false
+	}
+	numLits := 2 * s.NumVars()
+	out, in := make([][]implication, numLits), make([][]implication, numLits)
+	for _, refs := range [][]ClauseReference{s.clauses, s.learnts} {
+		for _, cr := range refs {
+			c := s.claAllocator.GetClause(cr)
+			if c.Size() != 2 {
+				continue
+			}
+			//(a | b) gives ~a -> b and ~b -> a
+			a, b := c.At(0), c.At(1)
+			out[a.Flip().X] = append(out[a.Flip().X], implication{lit: b, id: c.id})
+			out[b.Flip().X] = append(out[b.Flip().X], implication{lit: a, id: c.id})
+			in[b.X] = append(in[b.X], implication{lit: a.Flip(), id: c.id})
+			in[a.X] = append(in[a.X], implication{lit: b.Flip(), id: c.id})
+		}
+	}
+	components := strongComponents(out)
+	component := make([]int, numLits)
+	for i, members := range components {
+		for _, p := range members {
+			component[p.X] = i
+		}
+	}
+
+	removable := s.removableVars()
+	//repr[p] is the literal which replaces p and implied[p] is the ID of the binary clause (~p | repr[p])
+	repr := make([]Lit, numLits)
+	for x := range repr {
+		repr[x] = Lit{X: x}
+	}
+	implied := make([]uint64, numLits)
+	var derived []numberedClause
+	substituted := 0
+	for _, members := range components {
+		if len(members) == 1 {
+			continue
+		}
+		//The representative is the smallest variable, and a variable which must be kept is preferred
+		r := members[0]
+		for _, p := range members[1:] {
+			if removable[r.Var()] && !removable[p.Var()] || removable[r.Var()] == removable[p.Var()] && p.Var() < r.Var() {
+				r = p
+			}
+		}
+		if component[r.X] == component[r.Flip().X] {
+			//r -> ~r and ~r -> r
+			return s.substituteConflict(r, in, component)
+		}
+		if r.Sign() {
+			//The component of the negations gives the same equivalences
+			continue
+		}
+		//toRepr[p] is the ID of (~p | r) and fromRepr[p] is the ID of (~r | p)
+		toRepr := s.deriveImplications(r, in, component, true)
+		fromRepr := s.deriveImplications(r, out, component, false)
+		for _, p := range members {
+			if p == r {
+				continue
+			}
+			if !removable[p.Var()] {
+				derived = append(derived, numberedClause{lits: implicationLits(p, r), id: toRepr[p]}, numberedClause{lits: implicationLits(r, p), id: fromRepr[p]})
+				continue
+			}
+			repr[p.X], repr[p.Flip().X] = r, r.Flip()
+			implied[p.X], implied[p.Flip().X] = toRepr[p], fromRepr[p]
+			s.elimStack = append(s.elimStack,
+				elimEntry{witness: p.Flip(), lits: []Lit{p.Flip(), r}, id: toRepr[p], removed: true},
+				elimEntry{witness: p, lits: []Lit{p, r.Flip()}, id: fromRepr[p], removed: true})
+			s.eliminated[p.Var()] = true
+			s.SetDecisionVar(p.Var(), false)
+			substituted++
+		}
+	}
+	if substituted == 0 {
+		s.deleteImplications(derived)
+		return true
+	}
+	s.statistics.SubstitutedVarCount += uint64(substituted)
+
+	//The clauses of the substituted variables are replaced by new clauses and the learnt clauses of them are removed
+	var replacements []numberedClause
+	for _, refs := range [][]ClauseReference{s.clauses, s.learnts} {
+		for _, cr := range refs {
+			c := s.claAllocator.GetClause(cr)
+			var lits []Lit
+			var hints []uint64
+			changed, tautology := false, false
+			for i := 0; i < c.Size(); i++ {
+				q := c.At(i)
+				p := repr[q.X]
+				if p != q {
+					changed = true
+					hints = append(hints, implied[q.X])
+				}
+				if containsLit(lits, p.Flip()) {
+					tautology = true
+				} else if !containsLit(lits, p) {
+					lits = append(lits, p)
+				}
+			}
+			if !changed {
+				continue
+			}
+			if !c.Learnt() && !tautology {
+				id := s.newClauseID()
+				if s.proof != nil {
+					s.proof.add(id, lits, append(hints, c.id))
+				}
+				replacements = append(replacements, numberedClause{lits: lits, id: id})
+			}
+			s.removeClause(cr)
+		}
+	}
+	s.deleteImplications(derived)
+	s.clauses = s.liveClauses(s.clauses)
+	s.learnts = s.liveClauses(s.learnts)
+	for _, r := range replacements {
+		if !s.addClauseWithID(r.id, r.lits) {
+			return false
+		}
+	}
+	return true
+}
+
+//strongComponents returns the strongly connected components of the implication graph by Tarjan's algorithm
+func strongComponents(out [][]implication) [][]Lit {
+	index, low := make([]int, len(out)), make([]int, len(out))
+	onStack := make([]bool, len(out))
+	var stack []int
+	var components [][]Lit
+	counter := 0
+	var visit func(v int)
+	visit = func(v int) {
+		counter++
+		index[v], low[v] = counter, counter
+		stack = append(stack, v)
+		onStack[v] = true
+		for _, e := range out[v] {
+			w := e.lit.X
+			if index[w] == 0 {
+				visit(w)
+				if low[w] < low[v] {
+					low[v] = low[w]
+				}
+			} else if onStack[w] && index[w] < low[v] {
+				low[v] = index[w]
+			}
+		}
+		if low[v] != index[v] {
+			return
+		}
+		var members []Lit
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			members = append(members, Lit{X: w})
+			if w == v {
+				break
+			}
+		}
+		components = append(components, members)
+	}
+	for v := range out {
+		if index[v] == 0 {
+			visit(v)
+		}
+	}
+	return components
+}
+
+//deriveImplications writes the binary clauses (~p | r) (toRepr) or (~r | p) (!toRepr) for every literal p of the component of r
+//by the breadth first search from r over the edges in the component, and returns their IDs
+//Each clause is derived from the clause of its parent in the search tree and the edge to the parent
+func (s *Solver) deriveImplications(r Lit, edges [][]implication, component []int, toRepr bool) map[Lit]uint64 {
+	ids := map[Lit]uint64{}
+	queue := []Lit{r}
+	for i := 0; i < len(queue); i++ {
+		parent := queue[i]
+		for _, e := range edges[parent.X] {
+			p := e.lit
+			if _, reached := ids[p]; reached || p == r || component[p.X] != component[r.X] {
+				continue
+			}
+			queue = append(queue, p)
+			//p -> parent -> r (toRepr) or r -> parent -> p (!toRepr)
+			//The clause of the parent propagates first in both, since it is the unit clause r when parent is ~r
+			lits := implicationLits(r, p)
+			if toRepr {
+				lits = implicationLits(p, r)
+			}
+			var hints []uint64
+			if parent != r {
+				hints = append(hints, ids[parent])
+			}
+			hints = append(hints, e.id)
+			ids[p] = s.newClauseID()
+			if s.proof != nil {
+				s.proof.add(ids[p], lits, hints)
+			}
+		}
+	}
+	return ids
+}
+
+//implicationLits returns the clause (~p | q) of the implication p -> q
+func implicationLits(p, q Lit) []Lit {
+	if p.Flip() == q {
+		return []Lit{q}
+	}
+	return []Lit{p.Flip(), q}
+}
+
+//deleteImplications deletes the derived binary clauses which are not kept in the elimination stack from the proof
+func (s *Solver) deleteImplications(derived []numberedClause) {
+	if s.proof == nil {
+		return
+	}
+	for _, c := range derived {
+		s.proof.delete(c.id, c.lits)
+	}
+}
+
+//substituteConflict derives the unit clause ~r from the path r -> ~r and propagates it to the conflict by the path ~r -> r
+func (s *Solver) substituteConflict(r Lit, in [][]implication, component []int) bool {
+	//r -> ~r gives the unit clause ~r
+	id := s.deriveImplications(r.Flip(), in, component, true)[r]
+	s.uncheckedEnqueue(r.Flip(), ClaRefUndef)
+	s.unitID[r.Var()] = id
+	if confl := s.propagate(); confl != ClaRefUndef && s.proof != nil {
+		s.proofConflict(confl)
+	}
+	s.ok = false
+	return false
+}
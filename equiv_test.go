@@ -0,0 +1,132 @@
+package gatosat
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/togatoga/gatosat/lrat"
+)
+
+//randomEquivalences returns random clauses with the chains of the equivalences x <-> y of random signs
+func randomEquivalences(rnd *rand.Rand, numVars, numClauses int) [][]Lit {
+	clauses := randomClauses(rnd, numVars, numClauses)
+	for i := 0; i < numVars/2; i++ {
+		x := *NewLit(Var(rnd.Intn(numVars)), false)
+		y := *NewLit(Var(rnd.Intn(numVars)), rnd.Intn(2) == 0)
+		if x.Var() != y.Var() {
+			clauses = append(clauses, []Lit{x.Flip(), y}, []Lit{x, y.Flip()})
+		}
+	}
+	rnd.Shuffle(len(clauses), func(i, j int) { clauses[i], clauses[j] = clauses[j], clauses[i] })
+	return clauses
+}
+
+func TestSubstitute(t *testing.T) {
+	rnd := rand.New(rand.NewSource(5))
+	numVars := 12
+	substituted := uint64(0)
+	for i := 0; i < 300; i++ {
+		clauses := randomEquivalences(rnd, numVars, 1+rnd.Intn(20))
+		opts, err := PresetOptions("industrial")
+		if err != nil {
+			t.Fatal(err)
+		}
+		opts.Elimination = i%2 == 0
+		s, err := NewSolverWithOptions(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var proof bytes.Buffer
+		s.SetClauseStore(NewMemoryClauseStore())
+		s.SetProof(&proof, ProofLRAT)
+		for v := 0; v < numVars; v++ {
+			s.NewVar()
+		}
+		for _, c := range clauses {
+			s.AddClause(append([]Lit(nil), c...))
+		}
+		status := s.Solve()
+		if expected := bruteForce(numVars, clauses, nil); status != expected {
+			t.Fatalf("The solver returns a wrong value: %v (expected %v) clauses %v", status, expected, clauses)
+		}
+		if status == LitBoolTrue {
+			if err := s.VerifyModel(); err != nil {
+				t.Fatalf("The extended model is wrong: %v", err)
+			}
+		} else {
+			s.FlushProof()
+			if err := lrat.NewChecker(dimacsClauses(clauses)).Check(&proof); err != nil {
+				t.Fatalf("The LRAT proof is not verified: %v", err)
+			}
+		}
+		if err := s.CheckInvariants(); err != nil {
+			t.Fatal(err)
+		}
+		substituted += s.Statistics().SubstitutedVarCount
+		if status != LitBoolTrue {
+			continue
+		}
+
+		//The assumptions on the substituted variables bring their equivalences back
+		assumptions := []Lit{*NewLit(Var(rnd.Intn(numVars)), rnd.Intn(2) == 0), *NewLit(Var(rnd.Intn(numVars)), rnd.Intn(2) == 0)}
+		status = s.SolveWithAssumptions(assumptions)
+		if expected := bruteForce(numVars, clauses, assumptions); status != expected {
+			t.Fatalf("The solver returns a wrong value with the assumptions: %v (expected %v)", status, expected)
+		}
+		if status == LitBoolTrue {
+			checkModel(t, s.Model(), clauses, assumptions)
+		}
+	}
+	if substituted == 0 {
+		t.Fatalf("No variable is substituted")
+	}
+}
+
+func TestSubstituteLRATProof(t *testing.T) {
+	//The component of x1 contains ~x1 through x7 -> ~x1
+	problem := [][]int{{-1, -5, -7, 3}, {-7, 7}, {-1, -7}, {-2, -3, 6}, {-6, -3}, {2, -3}, {-3, -2}, {-7, 1, 8}, {-2, 7}, {-7, 7, -8},
+		{-3, -1, -3, 2}, {-4, -7, -2}, {7, -6}, {-6, 1, -5}, {2, -5}, {2, 7}, {8, 7}, {1, -6}, {2, -5}, {6, 1, -8}, {8, 1}, {-6, 3},
+		{-2, -7}, {-8, -4}, {8, -7, 5}, {-4, 4, -4}, {-6}, {-6}}
+	rnd := rand.New(rand.NewSource(6))
+	for i := 0; i < 300; i++ {
+		numVars := 8
+		var clauses [][]Lit
+		if i == 0 {
+			for _, c := range problem {
+				var lits []Lit
+				for _, x := range c {
+					lits = append(lits, *NewLitFromDimacs(x))
+				}
+				clauses = append(clauses, lits)
+			}
+		} else {
+			clauses = randomEquivalences(rnd, numVars, 1+rnd.Intn(12))
+		}
+		opts := DefaultOptions()
+		opts.Substitution = true
+		s, err := NewSolverWithOptions(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var proof bytes.Buffer
+		s.SetClauseStore(NewMemoryClauseStore())
+		s.SetProof(&proof, ProofLRAT)
+		for v := 0; v < numVars; v++ {
+			s.NewVar()
+		}
+		for _, c := range clauses {
+			s.AddClause(append([]Lit(nil), c...))
+		}
+		status := s.Solve()
+		if expected := bruteForce(numVars, clauses, nil); status != expected {
+			t.Fatalf("The solver returns a wrong value: %v (expected %v) clauses %v", status, expected, clauses)
+		}
+		if status == LitBoolFalse {
+			s.FlushProof()
+			if err := lrat.NewChecker(dimacsClauses(clauses)).Check(&proof); err != nil {
+				t.Fatalf("The LRAT proof is not verified: %v clauses %v", err, clauses)
+			}
+		}
+	}
+}
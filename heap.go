@@ -1,4 +1,4 @@
-package main
+package gatosat
 
 import (
 	"fmt"
@@ -16,6 +16,15 @@ func NewHeap() *Heap {
 	return &Heap{}
 }
 
+//Clone returns a deep copy of the heap
+func (h *Heap) Clone() *Heap {
+	return &Heap{
+		data:     append([]Var(nil), h.data...),
+		indices:  append([]int(nil), h.indices...),
+		activity: append([]float64(nil), h.activity...),
+	}
+}
+
 //Less returns a boolean indicating whether two variables are small
 func (h *Heap) Less(i, j int) bool {
 	return h.activity[i] > h.activity[j]
@@ -83,13 +92,14 @@ func (h *Heap) PushBack(x Var) {
 	}
 	h.data = append(h.data, x)
 	h.indices[x] = len(h.data) - 1
+	h.percolateUp(h.indices[x])
 }
 
 func (h *Heap) percolateUp(i int) {
 	x := h.data[i]
 	p := parentIndex(i)
 
-	for i != 0 && h.Less(int(x), p) {
+	for i != 0 && h.Less(int(x), int(h.data[p])) {
 		h.indices[h.data[p]] = i
 		h.data[i] = h.data[p]
 
@@ -133,8 +143,8 @@ func parentIndex(i int) int {
 	return (i - 1) >> 1
 }
 
-func (s *Solver) InsertVarOrder(x Var) {
-	if !s.VarOrder.InHeap(x) && s.Decision[x] {
-		s.VarOrder.PushBack(x)
+func (s *Solver) insertVarOrder(x Var) {
+	if !s.varOrder.InHeap(x) && s.decision[x] {
+		s.varOrder.PushBack(x)
 	}
 }
@@ -0,0 +1,33 @@
+package gatosat
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestHeapOrder(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	h := NewHeap()
+	numVars := 50
+	for x := 0; x < numVars; x++ {
+		h.PushBack(Var(x))
+		h.activity[x] = float64(rnd.Intn(100))
+		h.Decrease(Var(x))
+	}
+	//A pushed variable is moved up by its activity
+	h.RemoveMin()
+	h.activity = append(h.activity, 1000)
+	h.indices = append(h.indices, -1)
+	h.PushBack(Var(numVars))
+	last := 1e9
+	for !h.Empty() {
+		x := h.RemoveMin()
+		if h.Activity(x) > last {
+			t.Fatalf("The variables are not removed in the order of the activity: %v after %v", h.Activity(x), last)
+		}
+		last = h.Activity(x)
+	}
+	if last == 1e9 {
+		t.Fatal("The heap is empty")
+	}
+}
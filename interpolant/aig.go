@@ -0,0 +1,225 @@
+package interpolant
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/togatoga/gatosat"
+)
+
+//Lit is a literal of an AIG. It is 2*node + 1 if it is complemented
+//The node 0 is the constant false, so False is 0 and True is 1
+type Lit uint32
+
+const (
+	//False is the constant false
+	False Lit = 0
+	//True is the constant true
+	True Lit = 1
+)
+
+//Not returns the complement of the literal
+func (l Lit) Not() Lit {
+	return l ^ 1
+}
+
+func (l Lit) node() int {
+	return int(l >> 1)
+}
+
+func (l Lit) complemented() bool {
+	return l&1 == 1
+}
+
+//node is an input or an AND gate of an AIG
+type node struct {
+	input       gatosat.Var //The variable of an input. VarUndef for an AND gate
+	left, right Lit
+}
+
+//AIG is an and-inverter graph whose inputs are variables of the problem
+//AND gates are hashed structurally, so the same gate is never created twice
+type AIG struct {
+	nodes  []node
+	inputs map[gatosat.Var]Lit
+	gates  map[[2]Lit]Lit
+}
+
+//NewAIG returns an AIG which has only the constant node
+func NewAIG() *AIG {
+	return &AIG{
+		nodes:  []node{{input: gatosat.VarUndef}},
+		inputs: map[gatosat.Var]Lit{},
+		gates:  map[[2]Lit]Lit{},
+	}
+}
+
+//Input returns the literal of the input of the variable
+func (g *AIG) Input(v gatosat.Var) Lit {
+	if l, ok := g.inputs[v]; ok {
+		return l
+	}
+	l := Lit(2 * len(g.nodes))
+	g.nodes = append(g.nodes, node{input: v})
+	g.inputs[v] = l
+	return l
+}
+
+//And returns the conjunction of the literals
+func (g *AIG) And(a, b Lit) Lit {
+	if a > b {
+		a, b = b, a
+	}
+	switch {
+	case a == False || a == b.Not():
+		return False
+	case a == True || a == b:
+		return b
+	}
+	key := [2]Lit{a, b}
+	if l, ok := g.gates[key]; ok {
+		return l
+	}
+	l := Lit(2 * len(g.nodes))
+	g.nodes = append(g.nodes, node{input: gatosat.VarUndef, left: a, right: b})
+	g.gates[key] = l
+	return l
+}
+
+//Or returns the disjunction of the literals
+func (g *AIG) Or(a, b Lit) Lit {
+	return g.And(a.Not(), b.Not()).Not()
+}
+
+//NumGates returns the number of the AND gates in the cone of the root
+func (g *AIG) NumGates(root Lit) int {
+	n := 0
+	for _, id := range g.cone(root) {
+		if g.nodes[id].input == gatosat.VarUndef {
+			n++
+		}
+	}
+	return n
+}
+
+//Inputs returns the variables of the inputs in the cone of the root
+func (g *AIG) Inputs(root Lit) []gatosat.Var {
+	var vars []gatosat.Var
+	for _, id := range g.cone(root) {
+		if v := g.nodes[id].input; v != gatosat.VarUndef {
+			vars = append(vars, v)
+		}
+	}
+	return vars
+}
+
+//cone returns the nodes reachable from the root except the constant node in topological order
+func (g *AIG) cone(root Lit) []int {
+	visited := make([]bool, len(g.nodes))
+	visited[0] = true
+	var order []int
+	stack := []int{root.node()}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		if visited[id] {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		n := g.nodes[id]
+		if n.input == gatosat.VarUndef && (!visited[n.left.node()] || !visited[n.right.node()]) {
+			stack = append(stack, n.left.node(), n.right.node())
+			continue
+		}
+		visited[id] = true
+		order = append(order, id)
+		stack = stack[:len(stack)-1]
+	}
+	return order
+}
+
+//Eval returns the value of the root under the values of the variables
+func (g *AIG) Eval(root Lit, value func(v gatosat.Var) bool) bool {
+	values := make([]bool, len(g.nodes))
+	for _, id := range g.cone(root) {
+		n := g.nodes[id]
+		if n.input != gatosat.VarUndef {
+			values[id] = value(n.input)
+		} else {
+			values[id] = g.litValue(values, n.left) && g.litValue(values, n.right)
+		}
+	}
+	return g.litValue(values, root)
+}
+
+func (g *AIG) litValue(values []bool, l Lit) bool {
+	return values[l.node()] != l.complemented()
+}
+
+//WriteAIGER writes the cone of the root in the ASCII AIGER format with the root as the only output
+//The symbol table names every input by its DIMACS variable
+func (g *AIG) WriteAIGER(w io.Writer, root Lit) error {
+	order := g.cone(root)
+	var inputs, gates []int
+	for _, id := range order {
+		if g.nodes[id].input != gatosat.VarUndef {
+			inputs = append(inputs, id)
+		} else {
+			gates = append(gates, id)
+		}
+	}
+	//The inputs are numbered first and the gates follow them in topological order
+	index := map[int]Lit{0: False}
+	for i, id := range append(append([]int(nil), inputs...), gates...) {
+		index[id] = Lit(2 * (i + 1))
+	}
+	lit := func(l Lit) Lit {
+		return index[l.node()] | l&1
+	}
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "aag %d %d 0 1 %d\n", len(order), len(inputs), len(gates))
+	for _, id := range inputs {
+		fmt.Fprintf(out, "%d\n", index[id])
+	}
+	fmt.Fprintf(out, "%d\n", lit(root))
+	for _, id := range gates {
+		n := g.nodes[id]
+		fmt.Fprintf(out, "%d %d %d\n", index[id], lit(n.left), lit(n.right))
+	}
+	for i, id := range inputs {
+		fmt.Fprintf(out, "i%d x%d\n", i, g.nodes[id].input+1)
+	}
+	out.WriteString("o0 interpolant\n")
+	return out.Flush()
+}
+
+//CNF returns the clauses of the Tseitin encoding of the cone of the root which assert the root
+//The inputs are their variables and a new variable is numbered from firstVar for every AND gate
+func (g *AIG) CNF(root Lit, firstVar gatosat.Var) [][]gatosat.Lit {
+	vars := map[int]gatosat.Var{}
+	lit := func(l Lit) gatosat.Lit {
+		return *gatosat.NewLit(vars[l.node()], l.complemented())
+	}
+	var clauses [][]gatosat.Lit
+	switch root {
+	case True:
+		return nil
+	case False:
+		return [][]gatosat.Lit{{}}
+	}
+	next := firstVar
+	for _, id := range g.cone(root) {
+		n := g.nodes[id]
+		if n.input != gatosat.VarUndef {
+			vars[id] = n.input
+			continue
+		}
+		vars[id] = next
+		next++
+		x := *gatosat.NewLit(vars[id], false)
+		a, b := lit(n.left), lit(n.right)
+		//x <-> a and b
+		clauses = append(clauses, []gatosat.Lit{x.Flip(), a}, []gatosat.Lit{x.Flip(), b}, []gatosat.Lit{x, a.Flip(), b.Flip()})
+	}
+	return append(clauses, []gatosat.Lit{lit(root)})
+}
@@ -0,0 +1,251 @@
+//Package interpolant computes Craig interpolants from refutations of the solver
+//
+//Given an unsatisfiable pair of clause sets A and B, an interpolant I is a formula over the variables shared by A and B
+//such that A implies I and I and B are unsatisfiable.
+//The refutation is the LRAT proof of the solver, in which every learnt clause has its antecedents from the conflict analysis.
+//The antecedents of a clause are replayed as a chain of resolutions backwards from the last one,
+//and a partial interpolant is computed for every clause in the McMillan or the Pudlák system.
+package interpolant
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/togatoga/gatosat"
+)
+
+//System is the system of the partial interpolants
+type System int
+
+const (
+	//McMillan is the system of McMillan. The interpolant is stronger (closer to A)
+	McMillan System = iota
+	//Pudlak is the symmetric system of Pudlák
+	Pudlak
+)
+
+//varClass is the class of a variable. The variables which are not in A are local to B
+type varClass int
+
+const (
+	localB varClass = iota
+	localA
+	shared
+)
+
+//clause is a clause of the refutation with its partial interpolant
+type clause struct {
+	lits []gatosat.Lit
+	itp  Lit
+}
+
+//interpolator computes the partial interpolants of the clauses of a refutation
+type interpolator struct {
+	aig     *AIG
+	system  System
+	class   map[gatosat.Var]varClass
+	clauses map[uint64]*clause
+}
+
+//Interpolate computes an interpolant from an LRAT proof of the unsatisfiability of A and B
+//The clauses of A have the IDs 1, ..., len(a) and the clauses of B follow them in the proof
+//It returns the AIG and its root, whose inputs are the shared variables
+func Interpolate(a, b [][]gatosat.Lit, proof io.Reader, system System) (*AIG, Lit, error) {
+	it := &interpolator{aig: NewAIG(), system: system, class: map[gatosat.Var]varClass{}, clauses: map[uint64]*clause{}}
+	for _, c := range a {
+		for _, p := range c {
+			it.class[p.Var()] = localA
+		}
+	}
+	for _, c := range b {
+		for _, p := range c {
+			if class, ok := it.class[p.Var()]; ok && class == localA {
+				it.class[p.Var()] = shared
+			} else if !ok {
+				it.class[p.Var()] = localB
+			}
+		}
+	}
+	for i, c := range a {
+		it.clauses[uint64(i+1)] = &clause{lits: c, itp: it.clauseA(c)}
+	}
+	for i, c := range b {
+		it.clauses[uint64(len(a)+i+1)] = &clause{lits: c, itp: True}
+	}
+	return it.replay(proof)
+}
+
+//Compute solves A and B with the solver and computes an interpolant if they are unsatisfiable
+//The solver should be empty. The LRAT proof is written into a buffer while solving
+//It returns the status of the solver, and the AIG and its root if the status is LitBoolFalse
+//The status is LitBoolUndef if the search is stopped by ctx
+func Compute(ctx context.Context, s *gatosat.Solver, numVars int, a, b [][]gatosat.Lit, system System) (*AIG, Lit, gatosat.LitBool, error) {
+	var proof bytes.Buffer
+	s.SetProof(&proof, gatosat.ProofLRAT)
+	defer s.SetProof(nil, gatosat.ProofLRAT)
+	for s.NumVars() < numVars {
+		s.NewVar()
+	}
+	for _, c := range a {
+		s.AddClause(c)
+	}
+	for _, c := range b {
+		s.AddClause(c)
+	}
+	status := s.SolveContext(ctx)
+	if err := s.FlushProof(); err != nil {
+		return nil, False, status, err
+	}
+	if status != gatosat.LitBoolFalse {
+		return nil, False, status, nil
+	}
+	g, root, err := Interpolate(a, b, &proof, system)
+	return g, root, status, err
+}
+
+//clauseA returns the partial interpolant of a clause of A
+func (it *interpolator) clauseA(c []gatosat.Lit) Lit {
+	if it.system == Pudlak {
+		return False
+	}
+	//The disjunction of the shared literals
+	itp := False
+	for _, p := range c {
+		if it.class[p.Var()] == shared {
+			itp = it.aig.Or(itp, it.input(p))
+		}
+	}
+	return itp
+}
+
+func (it *interpolator) input(p gatosat.Lit) Lit {
+	l := it.aig.Input(p.Var())
+	if p.Sign() {
+		return l.Not()
+	}
+	return l
+}
+
+//replay reads the proof and computes the partial interpolant of every added clause
+func (it *interpolator) replay(proof io.Reader) (*AIG, Lit, error) {
+	in := bufio.NewScanner(proof)
+	in.Buffer(make([]byte, 1024*1024), 1024*1024*1024)
+	for lineNumber := 1; in.Scan(); lineNumber++ {
+		fields := strings.Fields(in.Text())
+		if len(fields) == 0 || fields[0] == "c" {
+			continue
+		}
+		nums := make([]int64, 0, len(fields))
+		deletion := len(fields) > 1 && fields[1] == "d"
+		for i, f := range fields {
+			if deletion && i == 1 {
+				continue
+			}
+			x, err := strconv.ParseInt(f, 10, 64)
+			if err != nil {
+				return nil, False, fmt.Errorf("line %d: The number is wrong: %s", lineNumber, f)
+			}
+			nums = append(nums, x)
+		}
+		if deletion {
+			for _, id := range nums[1:] {
+				delete(it.clauses, uint64(id))
+			}
+			continue
+		}
+		//id lits 0 hints 0
+		var lits []gatosat.Lit
+		i := 1
+		for ; i < len(nums) && nums[i] != 0; i++ {
+			lits = append(lits, *gatosat.NewLitFromDimacs(int(nums[i])))
+		}
+		var hints []uint64
+		for i++; i < len(nums) && nums[i] != 0; i++ {
+			if nums[i] < 0 {
+				return nil, False, fmt.Errorf("line %d: RAT steps are not supported", lineNumber)
+			}
+			hints = append(hints, uint64(nums[i]))
+		}
+		c, err := it.resolve(lits, hints)
+		if err != nil {
+			return nil, False, fmt.Errorf("line %d: clause %d: %v", lineNumber, nums[0], err)
+		}
+		if len(lits) == 0 {
+			return it.aig, c.itp, nil
+		}
+		it.clauses[uint64(nums[0])] = c
+	}
+	if err := in.Err(); err != nil {
+		return nil, False, err
+	}
+	return nil, False, fmt.Errorf("The proof doesn't derive the empty clause")
+}
+
+//resolve derives the clause from the antecedents by resolution backwards from the last antecedent
+//An antecedent is resolved with the resolvent on its literal whose negation is in the resolvent
+//and skipped if there is no such literal
+func (it *interpolator) resolve(lits []gatosat.Lit, hints []uint64) (*clause, error) {
+	if len(hints) == 0 {
+		return nil, fmt.Errorf("The clause has no antecedents")
+	}
+	last, ok := it.clauses[hints[len(hints)-1]]
+	if !ok {
+		return nil, fmt.Errorf("The antecedent %d doesn't exist", hints[len(hints)-1])
+	}
+	resolvent := map[gatosat.Lit]bool{}
+	for _, p := range last.lits {
+		resolvent[p] = true
+	}
+	itp := last.itp
+	for i := len(hints) - 2; i >= 0; i-- {
+		d, ok := it.clauses[hints[i]]
+		if !ok {
+			return nil, fmt.Errorf("The antecedent %d doesn't exist", hints[i])
+		}
+		pivot := gatosat.Lit{X: gatosat.LitUndef}
+		for _, p := range d.lits {
+			if resolvent[p.Flip()] {
+				pivot = p
+				break
+			}
+		}
+		if pivot.X == gatosat.LitUndef {
+			continue
+		}
+		delete(resolvent, pivot.Flip())
+		for _, p := range d.lits {
+			if p != pivot {
+				resolvent[p] = true
+			}
+		}
+		//The resolvent has ~pivot and d has pivot
+		itp = it.combine(pivot.Flip(), itp, d.itp)
+	}
+	for _, p := range lits {
+		delete(resolvent, p)
+	}
+	if len(resolvent) > 0 {
+		return nil, fmt.Errorf("The antecedents don't derive the clause by resolution")
+	}
+	return &clause{lits: lits, itp: itp}, nil
+}
+
+//combine returns the partial interpolant of the resolvent of a clause with p and the partial interpolant i1
+//and a clause with ~p and the partial interpolant i2
+func (it *interpolator) combine(p gatosat.Lit, i1, i2 Lit) Lit {
+	switch class := it.class[p.Var()]; {
+	case class == localA:
+		return it.aig.Or(i1, i2)
+	case class == localB || it.system == McMillan:
+		return it.aig.And(i1, i2)
+	default:
+		//(p or i1) and (~p or i2)
+		x := it.input(p)
+		return it.aig.And(it.aig.Or(x, i1), it.aig.Or(x.Not(), i2))
+	}
+}
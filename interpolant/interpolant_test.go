@@ -0,0 +1,95 @@
+package interpolant
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/togatoga/gatosat"
+)
+
+func randomClauses(rnd *rand.Rand, vars []gatosat.Var, numClauses int) [][]gatosat.Lit {
+	clauses := make([][]gatosat.Lit, numClauses)
+	for i := range clauses {
+		for j := 0; j < 1+rnd.Intn(3); j++ {
+			clauses[i] = append(clauses[i], *gatosat.NewLit(vars[rnd.Intn(len(vars))], rnd.Intn(2) == 0))
+		}
+	}
+	return clauses
+}
+
+func satisfied(clauses [][]gatosat.Lit, value func(v gatosat.Var) bool) bool {
+	for _, c := range clauses {
+		sat := false
+		for _, p := range c {
+			if value(p.Var()) != p.Sign() {
+				sat = true
+				break
+			}
+		}
+		if !sat {
+			return false
+		}
+	}
+	return true
+}
+
+func TestInterpolate(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	//The variables 0-3 are local to A, 4-7 are shared and 8-11 are local to B
+	const numVars = 12
+	var varsA, varsB []gatosat.Var
+	for v := gatosat.Var(0); v < 8; v++ {
+		varsA = append(varsA, v)
+		varsB = append(varsB, v+4)
+	}
+	numUnsat := 0
+	for i := 0; i < 300; i++ {
+		a := randomClauses(rnd, varsA, 6+rnd.Intn(20))
+		b := randomClauses(rnd, varsB, 6+rnd.Intn(20))
+		system := System(i % 2)
+		g, root, status, err := Compute(context.Background(), gatosat.NewSolver(), numVars, a, b, system)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status != gatosat.LitBoolFalse {
+			continue
+		}
+		numUnsat++
+		for _, v := range g.Inputs(root) {
+			if v < 4 || v >= 8 {
+				t.Fatalf("The interpolant has a local variable: %d", v)
+			}
+		}
+		for mask := 0; mask < 1<<numVars; mask++ {
+			value := func(v gatosat.Var) bool { return mask&(1<<uint(v)) != 0 }
+			itp := g.Eval(root, value)
+			if satisfied(a, value) && !itp {
+				t.Fatalf("A doesn't imply the interpolant (system %d): A %v B %v", system, a, b)
+			}
+			if satisfied(b, value) && itp {
+				t.Fatalf("The interpolant and B are satisfiable (system %d): A %v B %v", system, a, b)
+			}
+		}
+
+		//The Tseitin encoding is equisatisfiable with the interpolant together with B
+		s := gatosat.NewSolver()
+		for s.NumVars() < numVars {
+			s.NewVar()
+		}
+		for _, c := range append(g.CNF(root, numVars), b...) {
+			for _, p := range c {
+				for int(p.Var()) >= s.NumVars() {
+					s.NewVar()
+				}
+			}
+			s.AddClause(c)
+		}
+		if status := s.Solve(); status != gatosat.LitBoolFalse {
+			t.Fatalf("The Tseitin encoding of the interpolant and B are satisfiable")
+		}
+	}
+	if numUnsat < 50 {
+		t.Fatalf("Too few unsatisfiable problems: %d", numUnsat)
+	}
+}
@@ -0,0 +1,182 @@
+package gatosat
+
+import "fmt"
+
+//CheckInvariants validates the internal data structures of the solver and returns the first violation
+//It checks the watches of the clauses, the consistency of the trail, the assignments and the levels,
+//the reasons, the decision heap and the clause counters of Statistics
+//It is called periodically during the search if DebugCheckInterval is positive
+func (s *Solver) CheckInvariants() error {
+	if err := s.checkClauses(); err != nil {
+		return err
+	}
+	if err := s.checkTrail(); err != nil {
+		return err
+	}
+	if err := s.checkReasons(); err != nil {
+		return err
+	}
+	return s.checkHeap()
+}
+
+//checkClauses checks that every clause is watched exactly twice by the negations of its first two literals
+func (s *Solver) checkClauses() error {
+	type watch struct {
+		claRef ClauseReference
+		lit    Lit
+	}
+	watched := map[watch]int{}
+	numWatchers := 0
+	for i, ws := range s.watches.watches {
+		p := Lit{X: i}
+		for _, w := range ws {
+			if int(w.claRef) >= len(s.claAllocator.Clauses) || s.claAllocator.Clauses[w.claRef].IsRemoved() {
+				return fmt.Errorf("The watcher of %d points to a removed clause: %d", p.Dimacs(), w.claRef)
+			}
+			watched[watch{w.claRef, p}]++
+			numWatchers++
+		}
+	}
+	check := func(refs []ClauseReference, learnt bool) error {
+		for _, cr := range refs {
+			if int(cr) >= len(s.claAllocator.Clauses) || s.claAllocator.Clauses[cr].IsRemoved() {
+				return fmt.Errorf("The clause list has a removed clause: %d", cr)
+			}
+			c := s.claAllocator.GetClause(cr)
+			if c.Learnt() != learnt {
+				return fmt.Errorf("The clause is in the wrong list: %d learnt = %v", cr, c.Learnt())
+			}
+			if c.Size() < 2 || c.Size() > len(c.Data) {
+				return fmt.Errorf("The size of the clause is wrong: %d size = %d", cr, c.Size())
+			}
+			first, second := c.Data[0].Flip(), c.Data[1].Flip()
+			if first == second {
+				return fmt.Errorf("The clause has the same literal twice in the watches: %d %s", cr, dimacsString(c.Data[:c.Size()]))
+			}
+			for _, p := range []Lit{first, second} {
+				if n := watched[watch{cr, p}]; n != 1 {
+					return fmt.Errorf("The clause %d %s is watched %d times by %d", cr, dimacsString(c.Data[:c.Size()]), n, p.Dimacs())
+				}
+			}
+			numWatchers -= 2
+		}
+		return nil
+	}
+	if err := check(s.clauses, false); err != nil {
+		return err
+	}
+	if err := check(s.learnts, true); err != nil {
+		return err
+	}
+	if numWatchers != 0 {
+		return fmt.Errorf("There are %d watchers of the literals which are not watched", numWatchers)
+	}
+	if s.statistics.NumClauses != uint64(len(s.clauses)) {
+		return fmt.Errorf("The number of the clauses is %d but Statistics has %d", len(s.clauses), s.statistics.NumClauses)
+	}
+	if s.statistics.NumLearnts != uint64(len(s.learnts)) {
+		return fmt.Errorf("The number of the learnt clauses is %d but Statistics has %d", len(s.learnts), s.statistics.NumLearnts)
+	}
+	return nil
+}
+
+//checkTrail checks that the trail is consistent with the assignments and the levels
+func (s *Solver) checkTrail() error {
+	if s.qhead > len(s.trail) {
+		return fmt.Errorf("The head of the propagation queue is beyond the trail: %d > %d", s.qhead, len(s.trail))
+	}
+	for level := 1; level < len(s.trailLim); level++ {
+		if s.trailLim[level-1] > s.trailLim[level] || s.trailLim[level] > len(s.trail) {
+			return fmt.Errorf("The trail limit of the level %d is wrong: %d", level, s.trailLim[level])
+		}
+	}
+	onTrail := make([]bool, s.NumVars())
+	level := 0
+	for i, p := range s.trail {
+		for level < len(s.trailLim) && s.trailLim[level] <= i {
+			level++
+		}
+		x := p.Var()
+		if onTrail[x] {
+			return fmt.Errorf("The variable %d is on the trail twice", x+1)
+		}
+		onTrail[x] = true
+		if s.valueLit(p) != LitBoolTrue {
+			return fmt.Errorf("The literal %d on the trail is not true", p.Dimacs())
+		}
+		if s.level(x) != level {
+			return fmt.Errorf("The level of the variable %d is %d but it is assigned at the level %d", x+1, s.level(x), level)
+		}
+	}
+	for x := 0; x < s.NumVars(); x++ {
+		if !onTrail[x] && s.assigns[x] != LitBoolUndef {
+			return fmt.Errorf("The variable %d is assigned but not on the trail", x+1)
+		}
+	}
+	return nil
+}
+
+//checkReasons checks that every reason is a live clause which implies its literal by the literals assigned before it
+func (s *Solver) checkReasons() error {
+	for _, p := range s.trail {
+		x := p.Var()
+		//The lazy reasons of the external propagator are not asked, so the check doesn't change the solver
+		cr := s.varData[x].Reason
+		if cr == ClaRefUndef || cr == claRefLazy {
+			continue
+		}
+		if int(cr) >= len(s.claAllocator.Clauses) || s.claAllocator.Clauses[cr].IsRemoved() {
+			return fmt.Errorf("The reason of the variable %d is a removed clause: %d", x+1, cr)
+		}
+		c := s.claAllocator.GetClause(cr)
+		if c.Data[0] != p {
+			return fmt.Errorf("The reason of %d doesn't have it as the first literal: %s", p.Dimacs(), dimacsString(c.Data[:c.Size()]))
+		}
+		for i := 1; i < c.Size(); i++ {
+			q := c.Data[i]
+			if s.valueLit(q) != LitBoolFalse || s.level(q.Var()) > s.level(x) {
+				return fmt.Errorf("The reason of %d has the literal %d which is not false before it: %s", p.Dimacs(), q.Dimacs(), dimacsString(c.Data[:c.Size()]))
+			}
+		}
+	}
+	return nil
+}
+
+//checkHeap checks the indices and the order of the decision heap
+//Every unassigned decision variable must be in the heap
+func (s *Solver) checkHeap() error {
+	if err := s.varOrder.check(); err != nil {
+		return err
+	}
+	for x := 0; x < s.NumVars(); x++ {
+		if s.decision[x] && s.assigns[x] == LitBoolUndef && !s.varOrder.InHeap(Var(x)) {
+			return fmt.Errorf("The unassigned decision variable %d is not in the heap", x+1)
+		}
+	}
+	return nil
+}
+
+//check checks the indices and the heap property
+func (h *Heap) check() error {
+	for i, x := range h.data {
+		if int(x) >= len(h.indices) || h.indices[x] != i {
+			return fmt.Errorf("The heap index of the variable %d is wrong", x+1)
+		}
+		if i > 0 && h.Less(int(x), int(h.data[parentIndex(i)])) {
+			return fmt.Errorf("The variable %d has more activity than its parent in the heap", x+1)
+		}
+	}
+	for x, i := range h.indices {
+		if i >= 0 && (i >= len(h.data) || h.data[i] != Var(x)) {
+			return fmt.Errorf("The variable %d has a heap index but it is not in the heap", x+1)
+		}
+	}
+	return nil
+}
+
+//assertInvariants panics if an invariant is violated
+func (s *Solver) assertInvariants() {
+	if err := s.CheckInvariants(); err != nil {
+		panic(fmt.Errorf("The invariant is violated after %d conflicts: %v", s.statistics.ConflictCount, err))
+	}
+}
@@ -0,0 +1,71 @@
+package gatosat
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestCheckInvariants(t *testing.T) {
+	opts := DefaultOptions()
+	opts.DebugCheckInterval = 1
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		s, err := NewSolverWithOptions(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for v := 0; v < 30; v++ {
+			s.NewVar()
+		}
+		for _, c := range randomClauses(rnd, 30, 120) {
+			s.AddClause(c)
+		}
+		s.SolveWithAssumptions([]Lit{*NewLit(Var(rnd.Intn(30)), rnd.Intn(2) == 0)})
+		if err := s.CheckInvariants(); err != nil {
+			t.Fatalf("The invariant is violated: %v", err)
+		}
+	}
+
+	s, err := NewSolverWithOptions(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Push()
+	pigeonHole(s, 6, 5)
+	s.Solve()
+	s.Pop()
+	s.Solve()
+	s, _ = pigeonHoleWithPropagator(6, 5, false)
+	s.opts.DebugCheckInterval = 1
+	s.Solve()
+	if err := s.CheckInvariants(); err != nil {
+		t.Fatalf("The invariant is violated: %v", err)
+	}
+
+	//The lazy reasons of the external propagator are not asked by the check
+	s, p := pigeonHoleWithPropagator(3, 3, false)
+	s.newDecisionLevel()
+	s.uncheckedEnqueue(*NewLit(Var(0), false), ClaRefUndef)
+	s.uncheckedEnqueue(*NewLit(Var(3), true), claRefLazy)
+	p.reasons[*NewLit(Var(3), true)] = []Lit{*NewLit(Var(3), true), *NewLit(Var(0), true)}
+	if err := s.CheckInvariants(); err != nil || s.varData[3].Reason != claRefLazy {
+		t.Fatalf("The check changes the lazy reason: %v %v", err, s.varData[3].Reason)
+	}
+	s.cancelUntil(0)
+
+	//A broken watch is detected
+	s = NewSolver()
+	pigeonHole(s, 3, 3)
+	c := s.claAllocator.GetClause(s.clauses[0])
+	c.Data[0], c.Data[c.Size()-1] = c.Data[c.Size()-1], c.Data[0]
+	if err := s.CheckInvariants(); err == nil || !strings.Contains(err.Error(), "watched") {
+		t.Fatalf("The broken watch is not detected: %v", err)
+	}
+	s = NewSolver()
+	pigeonHole(s, 3, 2)
+	s.statistics.NumLearnts++
+	if err := s.CheckInvariants(); err == nil {
+		t.Fatalf("The wrong counter is not detected")
+	}
+}
@@ -0,0 +1,172 @@
+//Package main exposes the gatosat solver through the IPASIR C interface
+//Build it as a shared library with go build -buildmode=c-shared
+package main
+
+/*
+#include <stdint.h>
+#include <stdlib.h>
+
+typedef int (*ipasir_terminate_fn)(void *data);
+typedef void (*ipasir_learn_fn)(void *data, int32_t *clause);
+
+static int call_terminate(ipasir_terminate_fn terminate, void *data) {
+	return terminate(data);
+}
+
+static void call_learn(ipasir_learn_fn learn, void *data, int32_t *clause) {
+	learn(data, clause);
+}
+*/
+import "C"
+
+import (
+	"sync"
+	"unsafe"
+
+	"github.com/togatoga/gatosat"
+)
+
+//ipasirSolver is the state of a solver created by ipasir_init
+type ipasirSolver struct {
+	solver      *gatosat.Solver
+	clause      []gatosat.Lit // The clause which is being added by ipasir_add
+	assumptions []gatosat.Lit // The assumptions for the next ipasir_solve
+	learnBuffer *C.int32_t    // The zero-terminated clause passed to the learn callback
+}
+
+var (
+	solversMu sync.Mutex
+	solvers   = map[unsafe.Pointer]*ipasirSolver{}
+	signature = C.CString("gatosat-0.0.1")
+)
+
+func lookup(handle unsafe.Pointer) *ipasirSolver {
+	solversMu.Lock()
+	defer solversMu.Unlock()
+	return solvers[handle]
+}
+
+func (is *ipasirSolver) toLit(lit C.int32_t) gatosat.Lit {
+	p := *gatosat.NewLitFromDimacs(int(lit))
+	for int(p.Var()) >= is.solver.NumVars() {
+		is.solver.NewVar()
+	}
+	return p
+}
+
+//export ipasir_signature
+func ipasir_signature() *C.char {
+	return signature
+}
+
+//export ipasir_init
+func ipasir_init() unsafe.Pointer {
+	//The handle is allocated by C because a Go pointer must not be kept by C code
+	handle := C.malloc(1)
+	solversMu.Lock()
+	defer solversMu.Unlock()
+	solvers[handle] = &ipasirSolver{solver: gatosat.NewSolver()}
+	return handle
+}
+
+//export ipasir_release
+func ipasir_release(handle unsafe.Pointer) {
+	solversMu.Lock()
+	is := solvers[handle]
+	delete(solvers, handle)
+	solversMu.Unlock()
+	if is != nil && is.learnBuffer != nil {
+		C.free(unsafe.Pointer(is.learnBuffer))
+	}
+	C.free(handle)
+}
+
+//export ipasir_add
+func ipasir_add(handle unsafe.Pointer, litOrZero C.int32_t) {
+	is := lookup(handle)
+	if litOrZero != 0 {
+		is.clause = append(is.clause, is.toLit(litOrZero))
+		return
+	}
+	is.solver.AddClause(is.clause)
+	is.clause = is.clause[:0]
+}
+
+//export ipasir_assume
+func ipasir_assume(handle unsafe.Pointer, lit C.int32_t) {
+	is := lookup(handle)
+	is.assumptions = append(is.assumptions, is.toLit(lit))
+}
+
+//export ipasir_solve
+func ipasir_solve(handle unsafe.Pointer) C.int {
+	is := lookup(handle)
+	status := is.solver.SolveWithAssumptions(is.assumptions)
+	is.assumptions = is.assumptions[:0]
+	switch status {
+	case gatosat.LitBoolTrue:
+		return 10
+	case gatosat.LitBoolFalse:
+		return 20
+	}
+	return 0
+}
+
+//export ipasir_val
+func ipasir_val(handle unsafe.Pointer, lit C.int32_t) C.int32_t {
+	is := lookup(handle)
+	p := *gatosat.NewLitFromDimacs(int(lit))
+	value := is.solver.Value(p.Var())
+	if value == gatosat.LitBoolUndef {
+		return 0
+	}
+	if (value == gatosat.LitBoolTrue) == (lit > 0) {
+		return lit
+	}
+	return -lit
+}
+
+//export ipasir_failed
+func ipasir_failed(handle unsafe.Pointer, lit C.int32_t) C.int {
+	is := lookup(handle)
+	if is.solver.Failed(*gatosat.NewLitFromDimacs(int(lit))) {
+		return 1
+	}
+	return 0
+}
+
+//export ipasir_set_terminate
+func ipasir_set_terminate(handle unsafe.Pointer, data unsafe.Pointer, terminate C.ipasir_terminate_fn) {
+	is := lookup(handle)
+	if terminate == nil {
+		is.solver.SetTerminate(nil)
+		return
+	}
+	is.solver.SetTerminate(func() bool {
+		return C.call_terminate(terminate, data) != 0
+	})
+}
+
+//export ipasir_set_learn
+func ipasir_set_learn(handle unsafe.Pointer, data unsafe.Pointer, maxLength C.int, learn C.ipasir_learn_fn) {
+	is := lookup(handle)
+	if is.learnBuffer != nil {
+		C.free(unsafe.Pointer(is.learnBuffer))
+		is.learnBuffer = nil
+	}
+	if learn == nil {
+		is.solver.SetLearn(0, nil)
+		return
+	}
+	is.learnBuffer = (*C.int32_t)(C.malloc(C.size_t(maxLength+1) * C.size_t(unsafe.Sizeof(C.int32_t(0)))))
+	buffer := unsafe.Slice(is.learnBuffer, int(maxLength)+1)
+	is.solver.SetLearn(int(maxLength), func(lits []gatosat.Lit, lbd int) {
+		for i := range lits {
+			buffer[i] = C.int32_t(lits[i].Dimacs())
+		}
+		buffer[len(lits)] = 0
+		C.call_learn(learn, data, is.learnBuffer)
+	})
+}
+
+func main() {}
@@ -0,0 +1,103 @@
+/* A small IPASIR application which checks the gatosat shared library. */
+#include <stdio.h>
+#include <stdlib.h>
+
+#include "ipasir.h"
+
+static int failures = 0;
+static int learnt = 0;
+
+#define CHECK(cond)                                                         \
+  do {                                                                      \
+    if (!(cond)) {                                                          \
+      fprintf(stderr, "%s:%d: check failed: %s\n", __FILE__, __LINE__, #cond); \
+      failures++;                                                           \
+    }                                                                       \
+  } while (0)
+
+static void add_clause(void *solver, const int32_t *lits) {
+  while (*lits) ipasir_add(solver, *lits++);
+  ipasir_add(solver, 0);
+}
+
+static int never_terminate(void *data) {
+  (*(int *)data)++;
+  return 0;
+}
+
+static int always_terminate(void *data) {
+  (void)data;
+  return 1;
+}
+
+static void count_learnt(void *data, int32_t *clause) {
+  (void)data;
+  while (*clause) clause++;
+  learnt++;
+}
+
+/* pigeon hole problem: n+1 pigeons into n holes */
+static void add_pigeon_hole(void *solver, int n) {
+  int32_t clause[64];
+  for (int i = 0; i <= n; i++) {
+    for (int j = 0; j < n; j++) clause[j] = i * n + j + 1;
+    clause[n] = 0;
+    add_clause(solver, clause);
+  }
+  for (int j = 0; j < n; j++) {
+    for (int i = 0; i <= n; i++) {
+      for (int k = i + 1; k <= n; k++) {
+        int32_t binary[3] = {-(i * n + j + 1), -(k * n + j + 1), 0};
+        add_clause(solver, binary);
+      }
+    }
+  }
+}
+
+int main(void) {
+  printf("c %s\n", ipasir_signature());
+
+  void *solver = ipasir_init();
+  int32_t c1[] = {1, 2, 0};
+  int32_t c2[] = {-1, 3, 0};
+  add_clause(solver, c1);
+  add_clause(solver, c2);
+
+  int polls = 0;
+  ipasir_set_terminate(solver, &polls, never_terminate);
+  CHECK(ipasir_solve(solver) == 10);
+  CHECK(ipasir_val(solver, 1) == 1 || ipasir_val(solver, 2) == 2);
+  CHECK(ipasir_val(solver, 1) == -1 || ipasir_val(solver, 3) == 3);
+
+  ipasir_assume(solver, -2);
+  ipasir_assume(solver, -3);
+  CHECK(ipasir_solve(solver) == 20);
+  CHECK(ipasir_failed(solver, -2) == 1);
+  CHECK(ipasir_failed(solver, -3) == 1);
+
+  /* assumptions are cleared after solve */
+  CHECK(ipasir_solve(solver) == 10);
+  ipasir_assume(solver, -2);
+  CHECK(ipasir_solve(solver) == 10);
+  CHECK(ipasir_val(solver, 1) == 1);
+  CHECK(ipasir_val(solver, 3) == 3);
+  CHECK(polls > 0);
+  ipasir_release(solver);
+
+  solver = ipasir_init();
+  add_pigeon_hole(solver, 5);
+  ipasir_set_terminate(solver, NULL, always_terminate);
+  CHECK(ipasir_solve(solver) == 0);
+  ipasir_set_terminate(solver, NULL, NULL);
+  ipasir_set_learn(solver, NULL, 100, count_learnt);
+  CHECK(ipasir_solve(solver) == 20);
+  CHECK(learnt > 0);
+  ipasir_release(solver);
+
+  if (failures) {
+    printf("c %d checks failed\n", failures);
+    return 1;
+  }
+  printf("c all checks passed\n");
+  return 0;
+}
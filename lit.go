@@ -1,4 +1,4 @@
-package main
+package gatosat
 
 type Var int
 
@@ -34,6 +34,23 @@ func NewLit(x Var, sign bool) *Lit {
 	return &p
 }
 
+//NewLitFromDimacs returns a pointer of the Lit for a non-zero DIMACS literal
+//(e.g. 1 -> x0, -3 -> not x2)
+func NewLitFromDimacs(x int) *Lit {
+	if x > 0 {
+		return NewLit(Var(x-1), false)
+	}
+	return NewLit(Var(-x-1), true)
+}
+
+//Dimacs returns the DIMACS literal of l
+func (l *Lit) Dimacs() int {
+	if l.Sign() {
+		return -(int(l.Var()) + 1)
+	}
+	return int(l.Var()) + 1
+}
+
 //Equal a boolean indicating whether p is equal to l
 func (l *Lit) Equal(p Lit) bool {
 	if l.X != p.X {
@@ -74,18 +91,18 @@ func LitToInt(l Lit) int {
 	return l.X
 }
 
-func (s *Solver) ValueVar(p Var) LitBool {
-	return s.Assigns[p]
+func (s *Solver) valueVar(p Var) LitBool {
+	return s.assigns[p]
 }
 
-func (s *Solver) ValueLit(p Lit) LitBool {
-	if s.Assigns[p.Var()] == LitBoolUndef {
+func (s *Solver) valueLit(p Lit) LitBool {
+	if s.assigns[p.Var()] == LitBoolUndef {
 		return LitBoolUndef
-	} else if s.Assigns[p.Var()] == LitBoolTrue {
+	} else if s.assigns[p.Var()] == LitBoolTrue {
 		if !p.Sign() {
 			return LitBoolTrue
 		}
-	} else if s.Assigns[p.Var()] == LitBoolFalse {
+	} else if s.assigns[p.Var()] == LitBoolFalse {
 		if p.Sign() {
 			return LitBoolTrue
 		}
@@ -0,0 +1,333 @@
+//Package lrat checks LRAT proofs of unsatisfiability
+//
+//The checker is intentionally small and independent of the solver, so that it can be trusted.
+//It reads the proof in a single forward pass and verifies every added clause by unit propagation
+//over the clauses given as hints, in the order of the hints.
+//Clauses of the formula have the IDs 1, 2, ... in the order of the formula
+package lrat
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+//Error is an error of a proof step
+type Error struct {
+	Line int   //The line of the proof
+	ID   int64 //The ID of the clause of the step
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("line %d: clause %d: %s", e.Line, e.ID, e.Msg)
+}
+
+//Checker checks an LRAT proof
+type Checker struct {
+	clauses map[int64][]int
+	lastID  int64
+	assigns []int8 //The value of each variable: 1 true, -1 false, 0 unassigned
+	trail   []int
+	derived bool //The empty clause is derived
+
+	NumAdded   int //The number of the checked clauses
+	NumDeleted int //The number of the deleted clauses
+}
+
+//NewChecker returns a checker for the formula
+//A literal is a non-zero integer as in DIMACS
+func NewChecker(clauses [][]int) *Checker {
+	c := &Checker{clauses: map[int64][]int{}}
+	for i, lits := range clauses {
+		c.addClause(int64(i+1), lits)
+	}
+	c.lastID = int64(len(clauses))
+	return c
+}
+
+//ReadCNF reads a formula in DIMACS CNF format
+func ReadCNF(r io.Reader) ([][]int, error) {
+	var clauses [][]int
+	var lits []int
+	in := bufio.NewScanner(r)
+	in.Buffer(make([]byte, 1024*1024), 1024*1024*1024)
+	for lineNumber := 1; in.Scan(); lineNumber++ {
+		line := strings.TrimSpace(in.Text())
+		if line == "" || line[0] == 'c' || line[0] == 'p' {
+			continue
+		}
+		for _, f := range strings.Fields(line) {
+			x, err := strconv.Atoi(f)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: wrong literal: %s", lineNumber, f)
+			}
+			if x == 0 {
+				clauses = append(clauses, lits)
+				lits = nil
+			} else {
+				lits = append(lits, x)
+			}
+		}
+	}
+	if len(lits) > 0 {
+		return nil, fmt.Errorf("the last clause is not terminated by 0")
+	}
+	return clauses, in.Err()
+}
+
+func (c *Checker) addClause(id int64, lits []int) {
+	c.clauses[id] = lits
+	for _, x := range lits {
+		for abs(x) >= len(c.assigns) {
+			c.assigns = append(c.assigns, 0)
+		}
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+//value returns 1 if the literal is true, -1 if false and 0 if unassigned
+func (c *Checker) value(x int) int8 {
+	v := c.assigns[abs(x)]
+	if x < 0 {
+		return -v
+	}
+	return v
+}
+
+func (c *Checker) assign(x int) {
+	if x > 0 {
+		c.assigns[x] = 1
+	} else {
+		c.assigns[-x] = -1
+	}
+	c.trail = append(c.trail, x)
+}
+
+//backtrack unassigns the literals assigned after the first n literals of the trail
+func (c *Checker) backtrack(n int) {
+	for _, x := range c.trail[n:] {
+		c.assigns[abs(x)] = 0
+	}
+	c.trail = c.trail[:n]
+}
+
+//Check reads the proof and checks every step
+//It returns an error if a step is wrong or the proof doesn't derive the empty clause
+func (c *Checker) Check(r io.Reader) error {
+	in := bufio.NewScanner(r)
+	in.Buffer(make([]byte, 1024*1024), 1024*1024*1024)
+	for lineNumber := 1; in.Scan(); lineNumber++ {
+		fields := strings.Fields(in.Text())
+		if len(fields) == 0 || fields[0] == "c" {
+			continue
+		}
+		if err := c.step(fields); err != nil {
+			err.Line = lineNumber
+			return err
+		}
+	}
+	if err := in.Err(); err != nil {
+		return err
+	}
+	if !c.derived {
+		return fmt.Errorf("the proof doesn't derive the empty clause")
+	}
+	return nil
+}
+
+//Derived returns true if the empty clause is derived
+func (c *Checker) Derived() bool {
+	return c.derived
+}
+
+func (c *Checker) step(fields []string) *Error {
+	nums := make([]int64, 0, len(fields))
+	deletion := len(fields) > 1 && fields[1] == "d"
+	for i, f := range fields {
+		if deletion && i == 1 {
+			continue
+		}
+		x, err := strconv.ParseInt(f, 10, 64)
+		if err != nil {
+			return &Error{Msg: fmt.Sprintf("wrong number: %s", f)}
+		}
+		nums = append(nums, x)
+	}
+	id := nums[0]
+	if deletion {
+		if nums[len(nums)-1] != 0 {
+			return &Error{ID: id, Msg: "the deletion is not terminated by 0"}
+		}
+		for _, d := range nums[1 : len(nums)-1] {
+			if _, ok := c.clauses[d]; !ok {
+				return &Error{ID: id, Msg: fmt.Sprintf("the deleted clause %d doesn't exist", d)}
+			}
+			delete(c.clauses, d)
+			c.NumDeleted++
+		}
+		return nil
+	}
+
+	//id lits 0 hints 0
+	end := 1
+	for end < len(nums) && nums[end] != 0 {
+		end++
+	}
+	if end >= len(nums)-1 || nums[len(nums)-1] != 0 {
+		return &Error{ID: id, Msg: "the clause or the hints are not terminated by 0"}
+	}
+	if id <= c.lastID {
+		return &Error{ID: id, Msg: fmt.Sprintf("the ID is not greater than the last ID %d", c.lastID)}
+	}
+	lits := make([]int, 0, end-1)
+	for _, x := range nums[1:end] {
+		lits = append(lits, int(x))
+	}
+	hints := nums[end+1 : len(nums)-1]
+	if msg := c.checkClause(lits, hints); msg != "" {
+		return &Error{ID: id, Msg: msg}
+	}
+	c.addClause(id, lits)
+	c.lastID = id
+	c.NumAdded++
+	if len(lits) == 0 {
+		c.derived = true
+	}
+	return nil
+}
+
+//checkClause checks that the clause is implied by the hints
+//It returns the reason of the failure or an empty string
+func (c *Checker) checkClause(lits []int, hints []int64) string {
+	for _, x := range lits {
+		for abs(x) >= len(c.assigns) {
+			c.assigns = append(c.assigns, 0)
+		}
+	}
+	defer c.backtrack(0)
+	for _, x := range lits {
+		switch c.value(x) {
+		case 0:
+			c.assign(-x)
+		case 1:
+			//The clause is a tautology
+			return ""
+		}
+	}
+	//The hints before the first negative hint are shared by RUP and RAT
+	i := 0
+	for ; i < len(hints) && hints[i] > 0; i++ {
+		conflict, msg := c.propagateHint(hints[i])
+		if msg != "" {
+			return msg
+		}
+		if conflict {
+			return ""
+		}
+	}
+	if len(lits) == 0 {
+		return "the hints don't derive a conflict"
+	}
+	return c.checkRAT(lits, hints[i:])
+}
+
+//propagateHint assigns the unit literal of the hinted clause
+//It returns true if the clause is falsified
+func (c *Checker) propagateHint(hint int64) (bool, string) {
+	clause, ok := c.clauses[hint]
+	if !ok {
+		return false, fmt.Sprintf("the hint %d doesn't exist", hint)
+	}
+	unit := 0
+	for _, x := range clause {
+		switch c.value(x) {
+		case 1:
+			return false, fmt.Sprintf("the hint %d is satisfied", hint)
+		case 0:
+			if unit != 0 && unit != x {
+				return false, fmt.Sprintf("the hint %d is not unit", hint)
+			}
+			unit = x
+		}
+	}
+	if unit == 0 {
+		return true, ""
+	}
+	c.assign(unit)
+	return false, ""
+}
+
+//checkRAT checks that every resolvent on the first literal is implied by the hints of its group
+//A group is a negative hint -d followed by the positive hints for the resolvent with the clause d
+//A clause without any resolution candidate needs no hints
+func (c *Checker) checkRAT(lits []int, hints []int64) string {
+	pivot := lits[0]
+	groups := map[int64][]int64{}
+	var d int64
+	for _, h := range hints {
+		if h < 0 {
+			d = -h
+			groups[d] = []int64{}
+		} else {
+			groups[d] = append(groups[d], h)
+		}
+	}
+	base := len(c.trail)
+	for id, clause := range c.clauses {
+		hasNegatedPivot := false
+		for _, x := range clause {
+			if x == -pivot {
+				hasNegatedPivot = true
+			}
+		}
+		if !hasNegatedPivot {
+			continue
+		}
+		group, ok := groups[id]
+		if !ok && len(hints) == 0 {
+			return "the hints don't derive a conflict"
+		}
+		if !ok {
+			return fmt.Sprintf("the resolution candidate %d has no hints", id)
+		}
+		if !c.checkResolvent(clause, -pivot, group) {
+			return fmt.Sprintf("the hints of the resolution candidate %d don't derive a conflict", id)
+		}
+		c.backtrack(base)
+	}
+	return ""
+}
+
+func (c *Checker) checkResolvent(clause []int, negatedPivot int, hints []int64) bool {
+	for _, x := range clause {
+		if x == negatedPivot {
+			continue
+		}
+		switch c.value(x) {
+		case 0:
+			c.assign(-x)
+		case 1:
+			//The resolvent is a tautology or satisfied
+			return true
+		}
+	}
+	for _, h := range hints {
+		conflict, msg := c.propagateHint(h)
+		if msg != "" {
+			return false
+		}
+		if conflict {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package lrat
+
+import (
+	"strings"
+	"testing"
+)
+
+//The formula (1 2) (-1 2) (1 -2) (-1 -2)
+var formula = [][]int{{1, 2}, {-1, 2}, {1, -2}, {-1, -2}}
+
+func TestCheck(t *testing.T) {
+	proof := `5 2 0 1 2 0
+5 d 1 2 0
+6 0 5 3 4 0
+`
+	c := NewChecker(formula)
+	if err := c.Check(strings.NewReader(proof)); err != nil {
+		t.Fatalf("The proof is not verified: %v", err)
+	}
+	if !c.Derived() || c.NumAdded != 2 || c.NumDeleted != 2 {
+		t.Fatalf("The counters are wrong: %d added %d deleted", c.NumAdded, c.NumDeleted)
+	}
+}
+
+func TestCheckRAT(t *testing.T) {
+	//(3 1) is RAT on 3 because no clause contains -3
+	//(-3 1) is RAT on -3 because the resolvent (1) with (3 1) is RUP
+	//and (1) follows from both of them
+	proof := `5 3 1 0 0
+6 -3 1 0 -5 1 3 0
+7 1 0 5 6 0
+8 0 7 2 4 0
+`
+	c := NewChecker(formula)
+	if err := c.Check(strings.NewReader(proof)); err != nil {
+		t.Fatalf("The proof is not verified: %v", err)
+	}
+}
+
+func TestCheckError(t *testing.T) {
+	tests := []struct {
+		proof string
+		line  int
+		msg   string
+	}{
+		{"5 2 0 1 0\n", 1, "don't derive a conflict"},
+		{"5 2 0 9 0\n", 1, "doesn't exist"},
+		{"5 0 1 0\n", 1, "not unit"},
+		{"5 2 0 1 2 0\n5 d 1 0\n6 0 1 0\n", 3, "doesn't exist"},
+		{"5 2 0 1 2 0\n5 0 5 3 4 0\n", 2, "not greater"},
+		{"5 1 0 -2 1 0\n", 1, "no hints"},
+		{"5 2 0 1 2 0\n", 0, "empty clause"},
+	}
+	for _, test := range tests {
+		err := NewChecker(formula).Check(strings.NewReader(test.proof))
+		if err == nil || !strings.Contains(err.Error(), test.msg) {
+			t.Fatalf("The wrong proof %q returns a wrong error: %v", test.proof, err)
+		}
+		if e, ok := err.(*Error); ok && e.Line != test.line || !ok && test.line != 0 {
+			t.Fatalf("The wrong proof %q returns a wrong line: %v", test.proof, err)
+		}
+	}
+}
+
+func TestReadCNF(t *testing.T) {
+	clauses, err := ReadCNF(strings.NewReader("c comment\np cnf 2 2\n1 -2 0\n2\n0\n"))
+	if err != nil || len(clauses) != 2 || len(clauses[1]) != 1 || clauses[1][0] != 2 {
+		t.Fatalf("The clauses are wrong: %v %v", clauses, err)
+	}
+}
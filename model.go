@@ -0,0 +1,124 @@
+package gatosat
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+//ModelError is the error of a model which doesn't satisfy a clause
+type ModelError struct {
+	Index  int   //The index of the clause in the order the clauses are added
+	Clause []Lit //The violated clause
+}
+
+func (e *ModelError) Error() string {
+	return fmt.Sprintf("The model doesn't satisfy the clause %d: %s", e.Index+1, dimacsString(e.Clause))
+}
+
+//SetClauseStore keeps a copy of every clause added by AddClause in the store
+//It should be called before any clause is added. nil stops keeping the clauses
+func (s *Solver) SetClauseStore(store ClauseStore) {
+	s.clauseStore = store
+}
+
+//VerifyModel checks the last model against the clauses kept by the clause store
+//It returns a *ModelError with the first violated clause
+func (s *Solver) VerifyModel() error {
+	if s.clauseStore == nil {
+		return fmt.Errorf("The solver keeps no clauses to verify the model")
+	}
+	if s.clauseStoreErr != nil {
+		return s.clauseStoreErr
+	}
+	if len(s.model) == 0 && s.NumVars() > 0 {
+		return fmt.Errorf("The solver has no model")
+	}
+	return checkStore(s.clauseStore, s.model)
+}
+
+func checkStore(store ClauseStore, model []LitBool) error {
+	var violated *ModelError
+	index := 0
+	err := store.ForEach(func(lits []Lit) bool {
+		if !satisfiedByModel(lits, model) {
+			violated = &ModelError{Index: index, Clause: append([]Lit(nil), lits...)}
+			return false
+		}
+		index++
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	if violated != nil {
+		return violated
+	}
+	return nil
+}
+
+//satisfiedByModel returns true if a literal of the clause is true in the model
+func satisfiedByModel(lits []Lit, model []LitBool) bool {
+	for _, p := range lits {
+		if int(p.Var()) >= len(model) {
+			continue
+		}
+		if v := model[p.Var()]; v == LitBoolTrue && !p.Sign() || v == LitBoolFalse && p.Sign() {
+			return true
+		}
+	}
+	return false
+}
+
+//CheckModel checks the model against the clauses
+//It returns a *ModelError with the first violated clause. Unassigned variables satisfy no literal
+func CheckModel(clauses [][]Lit, model []LitBool) error {
+	for i, lits := range clauses {
+		if !satisfiedByModel(lits, model) {
+			return &ModelError{Index: i, Clause: lits}
+		}
+	}
+	return nil
+}
+
+//ReadModel reads a model in the output format of the solver
+//The literals are read from the "v" lines or the lines of integers, and the comment and status lines are skipped
+//The variables which don't appear in the model are LitBoolUndef
+func ReadModel(r io.Reader) ([]LitBool, error) {
+	var model []LitBool
+	in := bufio.NewScanner(r)
+	in.Buffer(make([]byte, 1024*1024), 1024*1024*1024)
+	for lineNumber := 1; in.Scan(); lineNumber++ {
+		fields := strings.Fields(in.Text())
+		if len(fields) == 0 || fields[0] == "c" || fields[0] == "s" {
+			continue
+		}
+		if fields[0] == "v" {
+			fields = fields[1:]
+		}
+		for _, f := range fields {
+			x, err := strconv.Atoi(f)
+			if err != nil {
+				return nil, fmt.Errorf("The model line %d has a wrong literal: %s", lineNumber, f)
+			}
+			if x == 0 {
+				continue
+			}
+			p := NewLitFromDimacs(x)
+			for int(p.Var()) >= len(model) {
+				model = append(model, LitBoolUndef)
+			}
+			v := LitBoolTrue
+			if p.Sign() {
+				v = LitBoolFalse
+			}
+			if model[p.Var()] != LitBoolUndef && model[p.Var()] != v {
+				return nil, fmt.Errorf("The model line %d assigns the variable %d twice", lineNumber, p.Var()+1)
+			}
+			model[p.Var()] = v
+		}
+	}
+	return model, in.Err()
+}
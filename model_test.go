@@ -0,0 +1,103 @@
+package gatosat
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestVerifyModel(t *testing.T) {
+	fileStore, err := NewFileClauseStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fileStore.Close()
+	stores := map[string]ClauseStore{"memory": NewMemoryClauseStore(), "file": fileStore}
+	for name, store := range stores {
+		rnd := rand.New(rand.NewSource(1))
+		//Every clause is satisfied by the hidden assignment of the first literal
+		clauses := randomClauses(rnd, 20, 40)
+		hidden := make([]bool, 20)
+		for i := range hidden {
+			hidden[i] = rnd.Intn(2) == 0
+		}
+		for _, c := range clauses {
+			c[0] = *NewLit(c[0].Var(), hidden[c[0].Var()])
+		}
+		s := NewSolver()
+		s.SetClauseStore(store)
+		for i := 0; i < 20; i++ {
+			s.NewVar()
+		}
+		for _, c := range clauses {
+			s.AddClause(c)
+		}
+		if status := s.Solve(); status != LitBoolTrue {
+			t.Fatalf("%s: The solver returns a wrong value: %v", name, status)
+		}
+		if err := s.VerifyModel(); err != nil {
+			t.Fatalf("%s: The model is not verified: %v", name, err)
+		}
+
+		//The clause violated by a broken model is reported
+		var kept [][]Lit
+		store.ForEach(func(lits []Lit) bool {
+			kept = append(kept, append([]Lit(nil), lits...))
+			return true
+		})
+		if len(kept) != len(clauses) {
+			t.Fatalf("%s: The store keeps %d clauses, want %d", name, len(kept), len(clauses))
+		}
+		model := append([]LitBool(nil), s.Model()...)
+		for _, p := range clauses[3] {
+			if p.Sign() {
+				model[p.Var()] = LitBoolTrue
+			} else {
+				model[p.Var()] = LitBoolFalse
+			}
+		}
+		s.model = model
+		err := s.VerifyModel()
+		if merr, ok := err.(*ModelError); !ok || satisfiedByModel(clauses[merr.Index], model) {
+			t.Fatalf("%s: The broken model is not detected: %v", name, err)
+		}
+	}
+
+	s := NewSolver()
+	pigeonHole(s, 5, 5)
+	if err := s.VerifyModel(); err == nil {
+		t.Fatalf("The solver without a clause store verifies the model")
+	}
+}
+
+func TestCheckModel(t *testing.T) {
+	_, clauses, err := ReadCNF(strings.NewReader("p cnf 3 3\n1 2 0\n-1 3 0\n-2 -3 0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	model, err := ReadModel(strings.NewReader("s SATISFIABLE\nv 1 -2\nv 3 0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckModel(clauses, model); err != nil {
+		t.Fatalf("The model is not verified: %v", err)
+	}
+
+	model, err = ReadModel(strings.NewReader("1 2 3 0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = CheckModel(clauses, model)
+	if merr, ok := err.(*ModelError); !ok || merr.Index != 2 {
+		t.Fatalf("The violated clause is not reported: %v", err)
+	}
+
+	//Unassigned variables satisfy no literal
+	model, _ = ReadModel(strings.NewReader("v 1 0\n"))
+	if err := CheckModel(clauses, model); err == nil {
+		t.Fatalf("The partial model is verified")
+	}
+	if _, err := ReadModel(strings.NewReader("v 1 -1 0\n")); err == nil {
+		t.Fatalf("The inconsistent model is read")
+	}
+}
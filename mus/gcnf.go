@@ -0,0 +1,124 @@
+package mus
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/togatoga/gatosat"
+)
+
+//Problem is a set of hard clauses and groups of soft clauses
+//A MUS is a minimal set of groups which is unsatisfiable together with the hard clauses
+type Problem struct {
+	NumVars int
+	Hard    [][]gatosat.Lit   //The clauses of the group 0, which are always included
+	Groups  [][][]gatosat.Lit //Groups[i] is the clauses of the group i+1
+}
+
+//NewProblem returns a problem in which every clause is a group by itself
+//The group i+1 is the i-th clause
+func NewProblem(numVars int, clauses [][]gatosat.Lit) *Problem {
+	p := &Problem{NumVars: numVars, Groups: make([][][]gatosat.Lit, len(clauses))}
+	for i, c := range clauses {
+		p.Groups[i] = [][]gatosat.Lit{c}
+	}
+	return p
+}
+
+//ReadProblem reads a problem in DIMACS CNF or group oriented CNF (GCNF) format
+//In CNF every clause is a group by itself. In GCNF every clause is prefixed by its group "{g}",
+//and the group 0 is the hard clauses
+func ReadProblem(r io.Reader) (*Problem, error) {
+	var p *Problem
+	var clauses [][]gatosat.Lit
+	gcnf := false
+	in := bufio.NewScanner(r)
+	in.Buffer(make([]byte, 1024*1024), 1024*1024*1024)
+	for lineNumber := 1; in.Scan(); lineNumber++ {
+		line := strings.TrimSpace(in.Text())
+		if len(line) == 0 || line[0] == 'c' {
+			continue
+		}
+		if line[0] == 'p' {
+			values := strings.Fields(line)
+			if len(values) < 4 || values[1] != "cnf" && values[1] != "gcnf" {
+				return nil, fmt.Errorf("PARSE ERROR! The problem line is wrong: %s", line)
+			}
+			gcnf = values[1] == "gcnf"
+			p = &Problem{}
+			if gcnf {
+				if len(values) != 5 {
+					return nil, fmt.Errorf("PARSE ERROR! The problem line is wrong: %s", line)
+				}
+				numGroups, err := strconv.Atoi(values[4])
+				if err != nil || numGroups < 0 {
+					return nil, fmt.Errorf("PARSE ERROR! The number of the groups is wrong: %s", line)
+				}
+				p.Groups = make([][][]gatosat.Lit, numGroups)
+			}
+			continue
+		}
+		if p == nil {
+			return nil, fmt.Errorf("PARSE ERROR! The clause at line %d precedes the problem line", lineNumber)
+		}
+		group := 0
+		if gcnf {
+			end := strings.IndexByte(line, '}')
+			if line[0] != '{' || end < 0 {
+				return nil, fmt.Errorf("PARSE ERROR! The clause at line %d has no group", lineNumber)
+			}
+			g, err := strconv.Atoi(line[1:end])
+			if err != nil || g < 0 || g > len(p.Groups) {
+				return nil, fmt.Errorf("PARSE ERROR! The group at line %d is wrong: %s", lineNumber, line[:end+1])
+			}
+			group = g
+			line = line[end+1:]
+		}
+		lits, err := parseClause(line)
+		if err != nil {
+			return nil, fmt.Errorf("PARSE ERROR! line %d: %v", lineNumber, err)
+		}
+		for _, q := range lits {
+			if int(q.Var()) >= p.NumVars {
+				p.NumVars = int(q.Var()) + 1
+			}
+		}
+		if !gcnf {
+			clauses = append(clauses, lits)
+		} else if group == 0 {
+			p.Hard = append(p.Hard, lits)
+		} else {
+			p.Groups[group-1] = append(p.Groups[group-1], lits)
+		}
+	}
+	if err := in.Err(); err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, fmt.Errorf("PARSE ERROR! There is no problem line")
+	}
+	if !gcnf {
+		return NewProblem(p.NumVars, clauses), nil
+	}
+	return p, nil
+}
+
+//parseClause parses the literals of a clause terminated by 0
+func parseClause(line string) ([]gatosat.Lit, error) {
+	values := strings.Fields(line)
+	if len(values) == 0 || values[len(values)-1] != "0" {
+		return nil, fmt.Errorf("The end of clause is not 0: %s", line)
+	}
+	lits := make([]gatosat.Lit, 0, len(values)-1)
+	for _, value := range values[:len(values)-1] {
+		x, err := strconv.Atoi(value)
+		if err != nil || x == 0 {
+			return nil, fmt.Errorf("The literal is wrong: %s", value)
+		}
+		lits = append(lits, *gatosat.NewLitFromDimacs(x))
+	}
+	return lits, nil
+}
@@ -0,0 +1,207 @@
+package mus
+
+import (
+	"context"
+
+	"github.com/togatoga/gatosat"
+)
+
+//Result is a MUS or a minimal correction set (MCS) found by the enumerator
+type Result struct {
+	MUS    bool  //true for a MUS and false for an MCS
+	Groups []int //The groups in ascending order. The groups are numbered from 1 as in GCNF
+}
+
+//Enumerator enumerates the MUSes and the MCSes of a problem with MARCO
+//
+//The map solver has a variable for every group, and its models are the subsets of the groups (seeds) not explored yet.
+//A seed is checked by the check solver. An unsatisfiable seed is shrunk to a MUS and its supersets are blocked,
+//and a satisfiable seed is grown to a maximal satisfiable subset whose complement is an MCS and its subsets are blocked.
+//The map solver prefers the larger seeds, so the MUSes tend to be found first
+type Enumerator struct {
+	mapSolver   *gatosat.Solver
+	checkSolver *gatosat.Solver
+	problem     *Problem
+	mapVars     []gatosat.Var //The variable of each group in the map solver
+	selectors   []gatosat.Lit //The selector of each group in the check solver
+
+	NumMUS    int //The number of the MUSes found
+	NumMCS    int //The number of the MCSes found
+	NumSolves int //The number of the calls to the check solver
+}
+
+//NewEnumerator returns an enumerator which uses the two solvers
+//The solvers should be empty. The problem is added to the check solver
+func NewEnumerator(mapSolver, checkSolver *gatosat.Solver, p *Problem) *Enumerator {
+	e := &Enumerator{
+		mapSolver:   mapSolver,
+		checkSolver: checkSolver,
+		problem:     p,
+		mapVars:     make([]gatosat.Var, len(p.Groups)),
+		selectors:   addProblem(checkSolver, p),
+	}
+	for g := range e.mapVars {
+		e.mapVars[g] = mapSolver.NewVar()
+		mapSolver.SetPolarity(e.mapVars[g], gatosat.LitBoolTrue)
+	}
+	return e
+}
+
+//Enumerate calls fn for every MUS and MCS until fn returns false
+//It returns LitBoolFalse if all MUSes and MCSes are enumerated, or LitBoolUndef if it is stopped by fn or ctx
+//The only MUS is empty if the hard clauses are unsatisfiable, and the only MCS is empty if the problem is satisfiable
+func (e *Enumerator) Enumerate(ctx context.Context, fn func(r Result) bool) gatosat.LitBool {
+	for {
+		status := e.mapSolver.SolveContext(ctx)
+		if status != gatosat.LitBoolTrue {
+			return status
+		}
+		seed := make([]bool, len(e.mapVars))
+		for g, v := range e.mapVars {
+			seed[g] = e.mapSolver.Value(v) == gatosat.LitBoolTrue
+		}
+
+		var result Result
+		var block []gatosat.Lit
+		switch e.solve(ctx, seed, -1) {
+		case gatosat.LitBoolFalse:
+			mus, ok := e.shrink(ctx)
+			if !ok {
+				return gatosat.LitBoolUndef
+			}
+			//Every superset of the MUS is unsatisfiable
+			for _, g := range mus {
+				block = append(block, *gatosat.NewLit(e.mapVars[g-1], true))
+			}
+			e.NumMUS++
+			result = Result{MUS: true, Groups: mus}
+		case gatosat.LitBoolTrue:
+			mss, ok := e.grow(ctx, seed)
+			if !ok {
+				return gatosat.LitBoolUndef
+			}
+			//Every subset of the MSS is satisfiable
+			var mcs []int
+			for g, in := range mss {
+				if !in {
+					mcs = append(mcs, g+1)
+					block = append(block, *gatosat.NewLit(e.mapVars[g], false))
+				}
+			}
+			e.NumMCS++
+			result = Result{MUS: false, Groups: mcs}
+		default:
+			return gatosat.LitBoolUndef
+		}
+		if !fn(result) {
+			return gatosat.LitBoolUndef
+		}
+		if !e.mapSolver.AddClause(block) {
+			return gatosat.LitBoolFalse
+		}
+	}
+}
+
+//solve solves the groups in the set and the group extra (-1 means no group) with the check solver
+func (e *Enumerator) solve(ctx context.Context, set []bool, extra int) gatosat.LitBool {
+	var assumptions []gatosat.Lit
+	for g, in := range set {
+		if in || g == extra {
+			assumptions = append(assumptions, e.selectors[g])
+		}
+	}
+	e.NumSolves++
+	return e.checkSolver.SolveWithAssumptionsContext(ctx, assumptions)
+}
+
+//failed returns the groups whose selectors are in the final conflict of the check solver
+func (e *Enumerator) failed() []bool {
+	failed := make([]bool, len(e.selectors))
+	index := map[gatosat.Lit]int{}
+	for g, p := range e.selectors {
+		index[p] = g
+	}
+	for _, p := range e.checkSolver.FailedAssumptions() {
+		failed[index[p]] = true
+	}
+	return failed
+}
+
+//shrink shrinks the core of the last unsatisfiable call to a MUS by deletion with clause-set refinement
+//It returns false if it is stopped
+func (e *Enumerator) shrink(ctx context.Context) ([]int, bool) {
+	set := e.failed()
+	critical := make([]bool, len(set))
+	for g := range set {
+		if !set[g] {
+			continue
+		}
+		set[g] = false
+		switch e.solve(ctx, set, -1) {
+		case gatosat.LitBoolFalse:
+			//The groups outside the final conflict are never needed
+			failed := e.failed()
+			for h := range set {
+				set[h] = set[h] && (failed[h] || critical[h])
+			}
+		case gatosat.LitBoolTrue:
+			set[g] = true
+			critical[g] = true
+		default:
+			return nil, false
+		}
+	}
+	var mus []int
+	for g, in := range set {
+		if in {
+			mus = append(mus, g+1)
+		}
+	}
+	return mus, true
+}
+
+//grow grows the satisfiable seed to a maximal satisfiable subset
+//The groups satisfied by the model of every satisfiable call are added at once
+//It returns false if it is stopped
+func (e *Enumerator) grow(ctx context.Context, seed []bool) ([]bool, bool) {
+	set := append([]bool(nil), seed...)
+	e.addSatisfied(set)
+	for g := range set {
+		if set[g] {
+			continue
+		}
+		switch e.solve(ctx, set, g) {
+		case gatosat.LitBoolTrue:
+			set[g] = true
+			e.addSatisfied(set)
+		case gatosat.LitBoolFalse:
+		default:
+			return nil, false
+		}
+	}
+	return set, true
+}
+
+//addSatisfied adds the groups satisfied by the model of the check solver to the set
+func (e *Enumerator) addSatisfied(set []bool) {
+	model := e.checkSolver.Model()
+	for g, clauses := range e.problem.Groups {
+		if set[g] {
+			continue
+		}
+		satisfied := true
+		for _, c := range clauses {
+			satisfied = false
+			for _, p := range c {
+				if model[p.Var()] == gatosat.LitBoolTrue && !p.Sign() || model[p.Var()] == gatosat.LitBoolFalse && p.Sign() {
+					satisfied = true
+					break
+				}
+			}
+			if !satisfied {
+				break
+			}
+		}
+		set[g] = satisfied
+	}
+}
@@ -0,0 +1,101 @@
+package mus
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/togatoga/gatosat"
+)
+
+//bruteForceMUSes returns the MUSes and the MCSes of the problem by solving every subset of the groups
+func bruteForceMUSes(p *Problem) (muses, mcses []string) {
+	n := len(p.Groups)
+	sat := make([]bool, 1<<uint(n))
+	for mask := range sat {
+		var groups []int
+		for g := 0; g < n; g++ {
+			if mask&(1<<uint(g)) != 0 {
+				groups = append(groups, g+1)
+			}
+		}
+		sat[mask] = solveGroups(p, groups) == gatosat.LitBoolTrue
+	}
+	all := 1<<uint(n) - 1
+	for mask := range sat {
+		minimal, maximal := true, true
+		for g := 0; g < n; g++ {
+			bit := 1 << uint(g)
+			if mask&bit != 0 && !sat[mask^bit] {
+				minimal = false
+			}
+			if mask&bit == 0 && sat[mask|bit] {
+				maximal = false
+			}
+		}
+		if !sat[mask] && minimal {
+			muses = append(muses, groupsString(mask, n))
+		}
+		if sat[mask] && maximal {
+			mcses = append(mcses, groupsString(all^mask, n))
+		}
+	}
+	sort.Strings(muses)
+	sort.Strings(mcses)
+	return muses, mcses
+}
+
+func groupsString(mask, n int) string {
+	var groups []int
+	for g := 0; g < n; g++ {
+		if mask&(1<<uint(g)) != 0 {
+			groups = append(groups, g+1)
+		}
+	}
+	return fmt.Sprint(groups)
+}
+
+func TestEnumerate(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		numVars := 2 + rnd.Intn(4)
+		var clauses [][]gatosat.Lit
+		for j := 0; j < 2+rnd.Intn(7); j++ {
+			var c []gatosat.Lit
+			for k := 0; k < 1+rnd.Intn(2); k++ {
+				c = append(c, *gatosat.NewLit(gatosat.Var(rnd.Intn(numVars)), rnd.Intn(2) == 0))
+			}
+			clauses = append(clauses, c)
+		}
+		p := NewProblem(numVars, clauses)
+		e := NewEnumerator(gatosat.NewSolver(), gatosat.NewSolver(), p)
+		var muses, mcses []string
+		status := e.Enumerate(context.Background(), func(r Result) bool {
+			if r.MUS {
+				muses = append(muses, fmt.Sprint(r.Groups))
+			} else {
+				mcses = append(mcses, fmt.Sprint(r.Groups))
+			}
+			return true
+		})
+		if status != gatosat.LitBoolFalse {
+			t.Fatalf("The enumeration is not completed: %v", status)
+		}
+		sort.Strings(muses)
+		sort.Strings(mcses)
+		wantMUSes, wantMCSes := bruteForceMUSes(p)
+		if fmt.Sprint(muses) != fmt.Sprint(wantMUSes) || fmt.Sprint(mcses) != fmt.Sprint(wantMCSes) {
+			t.Fatalf("The enumeration is wrong: MUSes %v want %v MCSes %v want %v", muses, wantMUSes, mcses, wantMCSes)
+		}
+	}
+
+	//The enumeration stops when fn returns false
+	p := NewProblem(1, [][]gatosat.Lit{{*gatosat.NewLit(0, false)}, {*gatosat.NewLit(0, true)}, {*gatosat.NewLit(0, true)}})
+	e := NewEnumerator(gatosat.NewSolver(), gatosat.NewSolver(), p)
+	count := 0
+	if status := e.Enumerate(context.Background(), func(r Result) bool { count++; return false }); status != gatosat.LitBoolUndef || count != 1 {
+		t.Fatalf("The enumeration doesn't stop: %v %d", status, count)
+	}
+}
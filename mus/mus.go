@@ -0,0 +1,273 @@
+//Package mus extracts minimal unsatisfiable subsets (MUS) of groups of clauses
+//
+//The extractor is deletion based on top of an incremental solver. Every group has a selector variable,
+//and the groups are tested one by one by solving without their selectors.
+//A group is removed if the rest is still unsatisfiable, otherwise it is critical (in every MUS of the rest).
+//Clause-set refinement removes the groups outside the final conflict of every unsatisfiable call,
+//and recursive model rotation finds more critical groups from the model of every satisfiable call.
+package mus
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/togatoga/gatosat"
+)
+
+type groupState int
+
+const (
+	groupUnknown groupState = iota
+	groupCritical
+	groupRemoved
+)
+
+//Extractor extracts a MUS of a problem
+type Extractor struct {
+	solver    *gatosat.Solver
+	problem   *Problem
+	selectors []gatosat.Lit //The selector of each group
+	state     []groupState
+
+	//The clauses of the problem for model rotation
+	lits    [][]gatosat.Lit
+	groupOf []int   //The group of each clause. -1 is the hard clauses
+	occ     [][]int //occ[p.X] is the clauses containing the literal p
+	numTrue []int   //The number of the literals true in the model of each clause
+	model   []bool
+
+	Refinement bool //Use clause-set refinement after every unsatisfiable call (default true)
+	Rotation   bool //Use recursive model rotation (default true)
+
+	NumSolves  int //The number of the calls to the solver
+	NumRefined int //The number of the groups removed by clause-set refinement
+	NumRotated int //The number of the groups found critical by model rotation
+}
+
+//NewExtractor returns an extractor which adds the problem to the solver
+//The solver should be empty. A selector variable is created for every group after the variables of the problem
+func NewExtractor(s *gatosat.Solver, p *Problem) *Extractor {
+	e := &Extractor{
+		solver:     s,
+		problem:    p,
+		selectors:  addProblem(s, p),
+		state:      make([]groupState, len(p.Groups)),
+		occ:        make([][]int, 2*p.NumVars),
+		Refinement: true,
+		Rotation:   true,
+	}
+	addClause := func(lits []gatosat.Lit, group int) {
+		for _, q := range lits {
+			e.occ[q.X] = append(e.occ[q.X], len(e.lits))
+		}
+		e.lits = append(e.lits, lits)
+		e.groupOf = append(e.groupOf, group)
+	}
+	for _, c := range p.Hard {
+		addClause(c, -1)
+	}
+	for g, clauses := range p.Groups {
+		for _, c := range clauses {
+			addClause(c, g)
+		}
+	}
+	return e
+}
+
+//addProblem adds the hard clauses and the groups to the solver and returns the selector of each group
+//The clauses of a group are added with the negation of its selector
+func addProblem(s *gatosat.Solver, p *Problem) []gatosat.Lit {
+	for s.NumVars() < p.NumVars {
+		s.NewVar()
+	}
+	check := func(lits []gatosat.Lit) {
+		for _, q := range lits {
+			if int(q.Var()) >= p.NumVars {
+				panic(fmt.Errorf("The literal is not a variable of the problem: %d", q.Dimacs()))
+			}
+		}
+	}
+	for _, c := range p.Hard {
+		check(c)
+		s.AddClause(c)
+	}
+	selectors := make([]gatosat.Lit, len(p.Groups))
+	for g, clauses := range p.Groups {
+		selectors[g] = *gatosat.NewLit(s.NewVar(), false)
+		for _, c := range clauses {
+			check(c)
+			s.AddClause(append(append(make([]gatosat.Lit, 0, len(c)+1), c...), selectors[g].Flip()))
+		}
+	}
+	return selectors
+}
+
+//Extract returns the groups of a MUS in ascending order. The groups are numbered from 1 as in GCNF
+//It returns LitBoolFalse and the MUS if the problem is unsatisfiable, or LitBoolTrue if it is satisfiable
+//or LitBoolUndef if the search is stopped
+//The MUS is empty if the hard clauses are unsatisfiable by themselves
+func (e *Extractor) Extract(ctx context.Context) ([]int, gatosat.LitBool) {
+	if status := e.solve(ctx, e.selectors); status != gatosat.LitBoolFalse {
+		return nil, status
+	}
+	//The groups outside the first core are never needed
+	e.refine()
+	for g := range e.state {
+		if e.state[g] != groupUnknown {
+			continue
+		}
+		var assumptions []gatosat.Lit
+		for h := range e.state {
+			if h != g && e.state[h] == groupUnknown {
+				assumptions = append(assumptions, e.selectors[h])
+			}
+		}
+		switch e.solve(ctx, assumptions) {
+		case gatosat.LitBoolFalse:
+			e.remove(g)
+			if e.Refinement {
+				e.refine()
+			}
+		case gatosat.LitBoolTrue:
+			e.setCritical(g)
+			if e.Rotation {
+				e.rotate()
+			}
+		default:
+			return nil, gatosat.LitBoolUndef
+		}
+	}
+	var mus []int
+	for g, state := range e.state {
+		if state == groupCritical {
+			mus = append(mus, g+1)
+		}
+	}
+	return mus, gatosat.LitBoolFalse
+}
+
+func (e *Extractor) solve(ctx context.Context, assumptions []gatosat.Lit) gatosat.LitBool {
+	e.NumSolves++
+	return e.solver.SolveWithAssumptionsContext(ctx, assumptions)
+}
+
+//remove removes the group from the problem
+func (e *Extractor) remove(g int) {
+	e.state[g] = groupRemoved
+	e.solver.AddClause([]gatosat.Lit{e.selectors[g].Flip()})
+}
+
+//setCritical makes the group a part of the MUS
+func (e *Extractor) setCritical(g int) {
+	e.state[g] = groupCritical
+	e.solver.AddClause([]gatosat.Lit{e.selectors[g]})
+}
+
+//refine removes the unknown groups whose selectors are not in the final conflict
+func (e *Extractor) refine() {
+	failed := map[gatosat.Lit]bool{}
+	for _, p := range e.solver.FailedAssumptions() {
+		failed[p] = true
+	}
+	for g, state := range e.state {
+		if state == groupUnknown && !failed[e.selectors[g]] {
+			e.remove(g)
+			e.NumRefined++
+		}
+	}
+}
+
+//active returns true if the clause belongs to the hard clauses or a group which is not removed
+func (e *Extractor) active(c int) bool {
+	return e.groupOf[c] < 0 || e.state[e.groupOf[c]] != groupRemoved
+}
+
+//rotate finds critical groups by rotating the model of the last call to the solver
+//The model satisfies every group except the critical group found by the call
+func (e *Extractor) rotate() {
+	solverModel := e.solver.Model()
+	e.model = make([]bool, e.problem.NumVars)
+	for v := range e.model {
+		e.model[v] = solverModel[v] == gatosat.LitBoolTrue
+	}
+	e.numTrue = make([]int, len(e.lits))
+	var falsified []int
+	for c, lits := range e.lits {
+		for _, q := range lits {
+			if e.isTrue(q) {
+				e.numTrue[c]++
+			}
+		}
+		if e.numTrue[c] == 0 && e.active(c) {
+			falsified = append(falsified, c)
+		}
+	}
+	if len(falsified) > 0 {
+		e.rotateFrom(falsified)
+	}
+}
+
+//rotateFrom flips every variable of the first falsified clause in the model
+//If the flipped model falsifies only the clauses of an unknown group, the group is critical
+//and the rotation continues from the flipped model
+func (e *Extractor) rotateFrom(falsified []int) {
+	for _, p := range e.lits[falsified[0]] {
+		var next []int
+		for _, c := range falsified {
+			if !contains(e.lits[c], p) {
+				next = append(next, c)
+			}
+		}
+		e.flip(p.Var())
+		for _, c := range e.occ[p.Flip().X] {
+			if e.numTrue[c] == 0 && e.active(c) {
+				next = append(next, c)
+			}
+		}
+		if g, ok := e.singleGroup(next); ok && e.state[g] == groupUnknown {
+			e.setCritical(g)
+			e.NumRotated++
+			e.rotateFrom(next)
+		}
+		e.flip(p.Var())
+	}
+}
+
+//singleGroup returns the group if all clauses belong to the same group which is not the hard clauses
+func (e *Extractor) singleGroup(clauses []int) (int, bool) {
+	if len(clauses) == 0 {
+		return 0, false
+	}
+	g := e.groupOf[clauses[0]]
+	for _, c := range clauses[1:] {
+		if e.groupOf[c] != g {
+			return 0, false
+		}
+	}
+	return g, g >= 0
+}
+
+//flip flips the value of the variable in the model
+func (e *Extractor) flip(v gatosat.Var) {
+	e.model[v] = !e.model[v]
+	p := *gatosat.NewLit(v, !e.model[v])
+	for _, c := range e.occ[p.X] {
+		e.numTrue[c]++
+	}
+	for _, c := range e.occ[p.Flip().X] {
+		e.numTrue[c]--
+	}
+}
+
+func (e *Extractor) isTrue(p gatosat.Lit) bool {
+	return e.model[p.Var()] != p.Sign()
+}
+
+func contains(lits []gatosat.Lit, p gatosat.Lit) bool {
+	for _, q := range lits {
+		if q == p {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package mus
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/togatoga/gatosat"
+)
+
+//solveGroups solves the hard clauses and the groups
+func solveGroups(p *Problem, groups []int) gatosat.LitBool {
+	s := gatosat.NewSolver()
+	for s.NumVars() < p.NumVars {
+		s.NewVar()
+	}
+	for _, c := range p.Hard {
+		s.AddClause(c)
+	}
+	for _, g := range groups {
+		for _, c := range p.Groups[g-1] {
+			s.AddClause(c)
+		}
+	}
+	return s.Solve()
+}
+
+//checkMUS checks that the groups are unsatisfiable and every group is necessary
+func checkMUS(t *testing.T, p *Problem, mus []int) {
+	t.Helper()
+	if solveGroups(p, mus) != gatosat.LitBoolFalse {
+		t.Fatalf("The MUS is satisfiable: %v", mus)
+	}
+	for i := range mus {
+		rest := append(append([]int(nil), mus[:i]...), mus[i+1:]...)
+		if solveGroups(p, rest) != gatosat.LitBoolTrue {
+			t.Fatalf("The MUS is not minimal: %v without %d", mus, mus[i])
+		}
+	}
+}
+
+func TestExtract(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	numRotated, numRefined := 0, 0
+	for i := 0; i < 200; i++ {
+		numVars := 3 + rnd.Intn(6)
+		var clauses [][]gatosat.Lit
+		for j := 0; j < 4*numVars; j++ {
+			var c []gatosat.Lit
+			for k := 0; k < 1+rnd.Intn(3); k++ {
+				c = append(c, *gatosat.NewLit(gatosat.Var(rnd.Intn(numVars)), rnd.Intn(2) == 0))
+			}
+			clauses = append(clauses, c)
+		}
+		p := NewProblem(numVars, clauses)
+		if i%2 == 1 {
+			//Half of the clauses are hard and the rest is grouped in pairs
+			p = &Problem{NumVars: numVars, Hard: clauses[:len(clauses)/2]}
+			for j := len(clauses) / 2; j+1 < len(clauses); j += 2 {
+				p.Groups = append(p.Groups, clauses[j:j+2])
+			}
+		}
+		e := NewExtractor(gatosat.NewSolver(), p)
+		e.Refinement = i%3 != 0
+		e.Rotation = i%4 != 0
+		mus, status := e.Extract(context.Background())
+		numRotated += e.NumRotated
+		numRefined += e.NumRefined
+		var all []int
+		for g := range p.Groups {
+			all = append(all, g+1)
+		}
+		if want := solveGroups(p, all); status != want {
+			t.Fatalf("The extractor returns a wrong value: %v want %v", status, want)
+		}
+		if status == gatosat.LitBoolFalse {
+			checkMUS(t, p, mus)
+		}
+	}
+	if numRotated == 0 || numRefined == 0 {
+		t.Fatalf("Model rotation or clause-set refinement never works: %d %d", numRotated, numRefined)
+	}
+}
+
+func TestReadProblem(t *testing.T) {
+	gcnf := `c a problem with the hard clauses and three groups
+p gcnf 2 5 3
+{0} 1 2 0
+{1} -1 0
+{2} -2 0
+{2} 1 0
+{3} -1 -2 0
+`
+	p, err := ReadProblem(strings.NewReader(gcnf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.NumVars != 2 || len(p.Hard) != 1 || len(p.Groups) != 3 || len(p.Groups[1]) != 2 {
+		t.Fatalf("The problem is read wrongly: %+v", p)
+	}
+	mus, status := NewExtractor(gatosat.NewSolver(), p).Extract(context.Background())
+	if status != gatosat.LitBoolFalse || len(mus) != 2 || mus[0] != 1 || mus[1] != 2 {
+		t.Fatalf("The MUS is wrong: %v %v", status, mus)
+	}
+
+	p, err = ReadProblem(strings.NewReader("p cnf 1 2\n1 0\n-1 0\n"))
+	if err != nil || len(p.Hard) != 0 || len(p.Groups) != 2 {
+		t.Fatalf("The problem is read wrongly: %+v %v", p, err)
+	}
+	if _, err := ReadProblem(strings.NewReader("p gcnf 1 1 1\n{2} 1 0\n")); err == nil {
+		t.Fatalf("The wrong group is read")
+	}
+}
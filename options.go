@@ -0,0 +1,160 @@
+package gatosat
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+const (
+	//RestartLuby is the restart policy whose limits follow the luby sequence
+	RestartLuby = "luby"
+	//RestartGeometric is the restart policy whose limits increase geometrically
+	RestartGeometric = "geometric"
+)
+
+//SolverOptions is the structure for the parameters of a solver
+type SolverOptions struct {
+	RestartPolicy            string    `json:"restart_policy"`              // The restart policy. "luby" or "geometric"
+	RestartFirst             int       `json:"restart_first"`               // The initial restart limit
+	RestartIncreaseRatio     float64   `json:"restart_increase_ratio"`      // The factor with which the restart limit is multiplied in each restart
+	VarDecayRatio            float64   `json:"var_decay_ratio"`             // The variable activity decay factor
+	ClauseDecayRatio         float64   `json:"clause_decay_ratio"`          // The clause activity decay factor
+	LearntSizeFactor         float64   `json:"learnt_size_factor"`          // The limit on the number of learnt clauses as a factor of the original clauses
+	LearntSizeIncreaseRatio  float64   `json:"learnt_size_increase_ratio"`  // The factor with which the limit on the learnt clauses is multiplied
+	LearntSizeAdjustStart    float64   `json:"learnt_size_adjust_start"`    // The number of conflicts until the limit on the learnt clauses is increased first
+	LearntSizeAdjustIncrease float64   `json:"learnt_size_adjust_increase"` // The factor with which the number of conflicts until the next increase is multiplied
+	RandomVarFreq            float64   `json:"random_var_freq"`             // The frequency with which the decision heuristic tries to choose a random variable
+	Seed                     float64   `json:"seed"`                        // The seed for the random variable selection
+	DebugCheckInterval       int       `json:"debug_check_interval"`        // The invariants are checked at every restart and every DebugCheckInterval conflicts if it is positive
+	Elimination              bool      `json:"elimination"`                 // The bounded variable elimination runs before the first search. A clause or an assumption on an eliminated or witness variable restores all the eliminated variables, and the elimination doesn't run again
+	Subsumption              bool      `json:"subsumption"`                 // The subsumption and the strengthening run before the search and periodically at level 0
+	Probing                  bool      `json:"probing"`                     // The failed literal probing runs before the search and at restarts
+	Substitution             bool      `json:"substitution"`                // The equivalent literals are substituted by their representatives before the search
+	BlockedClauseElimination bool      `json:"blocked_clause_elimination"`  // The blocked clauses are removed before the first search
+	CoveredClauseElimination bool      `json:"covered_clause_elimination"`  // The covered clauses are removed before the first search
+	Verbose                  io.Writer `json:"-"`                           // The search statistics are written to Verbose if it is not nil
+}
+
+//DefaultOptions returns the default options which are the same as the "minisat" preset
+//The preprocessing and the inprocessing are off, so the clauses stay as they are added (see the "industrial" preset)
+func DefaultOptions() SolverOptions {
+	return SolverOptions{
+		RestartPolicy:            RestartLuby,
+		RestartFirst:             100,
+		RestartIncreaseRatio:     2,
+		VarDecayRatio:            0.95,
+		ClauseDecayRatio:         0.999,
+		LearntSizeFactor:         0.3,
+		LearntSizeIncreaseRatio:  1.1,
+		LearntSizeAdjustStart:    100,
+		LearntSizeAdjustIncrease: 1.5,
+		RandomVarFreq:            0,
+		Seed:                     91648253,
+	}
+}
+
+var presets = map[string]func(*SolverOptions){
+	"minisat": func(o *SolverOptions) {},
+	//Frequent restarts and an aggressive reduction of the learnt clauses
+	"fast-restart": func(o *SolverOptions) {
+		o.RestartFirst = 50
+		o.VarDecayRatio = 0.9
+		o.LearntSizeFactor = 0.1
+		o.LearntSizeIncreaseRatio = 1.05
+	},
+	//Long stable phases for finding models
+	"sat-heavy": func(o *SolverOptions) {
+		o.RestartPolicy = RestartGeometric
+		o.RestartIncreaseRatio = 1.5
+		o.RandomVarFreq = 0.01
+	},
+	//Frequent restarts and a large learnt clause database for refutations
+	"unsat-heavy": func(o *SolverOptions) {
+		o.RestartFirst = 50
+		o.VarDecayRatio = 0.85
+		o.LearntSizeFactor = 0.5
+		o.LearntSizeIncreaseRatio = 1.2
+	},
+	//The preprocessing before the search and the inprocessing during the search for large structured instances
+	"industrial": func(o *SolverOptions) {
+		o.Elimination = true
+		o.Subsumption = true
+		o.Probing = true
+		o.Substitution = true
+	},
+}
+
+//PresetNames returns the names of the named configuration presets
+func PresetNames() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//PresetOptions returns the options of a named configuration preset
+func PresetOptions(name string) (SolverOptions, error) {
+	preset, ok := presets[name]
+	if !ok {
+		return SolverOptions{}, fmt.Errorf("Unknown preset: %s (available: %v)", name, PresetNames())
+	}
+	opts := DefaultOptions()
+	preset(&opts)
+	return opts, nil
+}
+
+//LoadOptions overwrites the options with the values of a JSON configuration
+//Missing fields keep their values
+func (o *SolverOptions) LoadOptions(r io.Reader) error {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(o); err != nil {
+		return fmt.Errorf("Invalid configuration: %v", err)
+	}
+	return nil
+}
+
+//Validate returns an error if the options are inconsistent
+func (o *SolverOptions) Validate() error {
+	if o.RestartPolicy != RestartLuby && o.RestartPolicy != RestartGeometric {
+		return fmt.Errorf("The restart policy must be %q or %q: %q", RestartLuby, RestartGeometric, o.RestartPolicy)
+	}
+	if o.RestartFirst < 1 {
+		return fmt.Errorf("The initial restart limit must be positive: %d", o.RestartFirst)
+	}
+	if o.RestartIncreaseRatio <= 1 {
+		return fmt.Errorf("The restart increase ratio must be greater than 1: %v", o.RestartIncreaseRatio)
+	}
+	if o.VarDecayRatio <= 0 || o.VarDecayRatio >= 1 {
+		return fmt.Errorf("The variable decay ratio must be in (0, 1): %v", o.VarDecayRatio)
+	}
+	if o.ClauseDecayRatio <= 0 || o.ClauseDecayRatio >= 1 {
+		return fmt.Errorf("The clause decay ratio must be in (0, 1): %v", o.ClauseDecayRatio)
+	}
+	if o.LearntSizeFactor <= 0 {
+		return fmt.Errorf("The learnt size factor must be positive: %v", o.LearntSizeFactor)
+	}
+	if o.LearntSizeIncreaseRatio < 1 {
+		return fmt.Errorf("The learnt size increase ratio must be at least 1: %v", o.LearntSizeIncreaseRatio)
+	}
+	if o.LearntSizeAdjustStart < 1 {
+		return fmt.Errorf("The learnt size adjust start must be at least 1: %v", o.LearntSizeAdjustStart)
+	}
+	if o.LearntSizeAdjustIncrease < 1 {
+		return fmt.Errorf("The learnt size adjust increase must be at least 1: %v", o.LearntSizeAdjustIncrease)
+	}
+	if o.RandomVarFreq < 0 || o.RandomVarFreq > 1 {
+		return fmt.Errorf("The random variable frequency must be in [0, 1]: %v", o.RandomVarFreq)
+	}
+	if o.Seed <= 0 {
+		return fmt.Errorf("The seed must be positive: %v", o.Seed)
+	}
+	if o.DebugCheckInterval < 0 {
+		return fmt.Errorf("The debug check interval must not be negative: %d", o.DebugCheckInterval)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package gatosat
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPresetOptions(t *testing.T) {
+	for _, name := range PresetNames() {
+		opts, err := PresetOptions(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := NewSolverWithOptions(opts)
+		if err != nil {
+			t.Fatalf("The preset %s is invalid: %v", name, err)
+		}
+		pigeonHole(s, 6, 5)
+		if status := s.Solve(); status != LitBoolFalse {
+			t.Fatalf("The solver with the preset %s returns a wrong value: %v", name, status)
+		}
+	}
+	if _, err := PresetOptions("unknown"); err == nil {
+		t.Fatal("An unknown preset is accepted")
+	}
+}
+
+func TestLoadOptions(t *testing.T) {
+	opts := DefaultOptions()
+	if err := opts.LoadOptions(strings.NewReader(`{"restart_policy": "geometric", "var_decay_ratio": 0.8}`)); err != nil {
+		t.Fatal(err)
+	}
+	if opts.RestartPolicy != RestartGeometric || opts.VarDecayRatio != 0.8 || opts.RestartFirst != DefaultOptions().RestartFirst {
+		t.Fatalf("The options are loaded wrongly: %+v", opts)
+	}
+	if err := opts.LoadOptions(strings.NewReader(`{"var_decay": 0.8}`)); err == nil {
+		t.Fatal("An unknown field is accepted")
+	}
+	opts.VarDecayRatio = 1.5
+	if _, err := NewSolverWithOptions(opts); err == nil {
+		t.Fatal("Invalid options are accepted")
+	}
+}
@@ -0,0 +1,24 @@
+package gatosat
+
+//preprocess simplifies the problem at level 0 before the search
+//The subsumption and the probing run if their intervals have passed since the last passes, the probing within its budget,
+//the equivalent literals are substituted, and the variable elimination and the blocked and covered clause elimination run before the first search
+//It returns false if the problem is unsatisfiable
+func (s *Solver) preprocess() bool {
+	if !s.simplify() {
+		return false
+	}
+	if s.opts.Subsumption && s.statistics.ConflictCount >= s.nextSubsumption && !s.subsume() {
+		return false
+	}
+	if s.opts.Probing && s.statistics.ConflictCount >= s.nextProbe && !s.probe() {
+		return false
+	}
+	if !s.substitute() {
+		return false
+	}
+	if !s.eliminate() {
+		return false
+	}
+	return s.eliminateBlocked()
+}
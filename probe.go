@@ -0,0 +1,196 @@
+package gatosat
+
+const (
+	//probeMinPropagations is the propagation budget of the first probing
+	probeMinPropagations = 100000
+	//probeEffort is the propagation budget of the probing at a restart relative to the propagations of the search since the last probing
+	probeEffort = 0.1
+	//probeMinBudget is the smallest propagation budget for which the probing runs
+	probeMinBudget = 1000
+	//probeInterval is the number of the conflicts between two probing passes in the search
+	probeInterval = 2000
+)
+
+//probe assigns the roots of the binary implication graph at level 1 one by one and propagates them within the propagation budget
+//A root whose propagation fails gives a unit clause by the conflict analysis,
+//and a literal q propagated by a longer clause gives the hyper-binary resolvent (~d | q) as a learnt clause,
+//where d is the dominator of the literals implying q in the binary implication tree of the root
+//The next probing starts from the root after the last probed one
+//It returns false if the problem is unsatisfiable
+func (s *Solver) probe() bool {
+	//The next probing is scheduled first, so that a skipped probing does not run again at every restart
+	s.nextProbe = s.statistics.ConflictCount + probeInterval
+	if s.propagator != nil {
+		return s.ok
+	}
+	searchPropagations := s.statistics.PropagationCount - s.statistics.ProbePropagationCount
+	budget := uint64(probeEffort * float64(searchPropagations-s.probeSearchPropagations))
+	if s.statistics.ProbeCount == 0 {
+		budget = probeMinPropagations
+	}
+	if budget < probeMinBudget {
+		return s.ok
+	}
+	s.probeSearchPropagations = searchPropagations
+	s.statistics.ProbeCount++
+	if !s.simplify() {
+		return false
+	}
+
+	roots := s.probeRoots()
+	start := 0
+	for start < len(roots) && roots[start].X < s.probeNext {
+		start++
+	}
+	propagations := s.statistics.PropagationCount
+	defer func() {
+		s.statistics.ProbePropagationCount += s.statistics.PropagationCount - propagations
+		s.learnts = s.liveClauses(s.learnts)
+	}()
+	for i := 0; i < len(roots) && s.statistics.PropagationCount-propagations < budget && s.withinBudget(); i++ {
+		p := roots[(start+i)%len(roots)]
+		s.probeNext = p.X + 1
+		if s.valueLit(p) != LitBoolUndef {
+			continue
+		}
+		s.statistics.ProbedLiteralCount++
+		s.newDecisionLevel()
+		s.uncheckedEnqueue(p, ClaRefUndef)
+		if confl := s.propagate(); confl != ClaRefUndef {
+			//The learnt clause of a conflict at level 1 is a unit clause
+			s.statistics.FailedLiteralCount++
+			learntClause, _ := s.analyze(confl)
+			id := s.newClauseID()
+			if s.proof != nil {
+				var hints []uint64
+				if s.proof.format == ProofLRAT {
+					hints = s.lratHints(confl, learntClause)
+				}
+				s.proof.add(id, learntClause, hints)
+			}
+			s.cancelUntil(0)
+			s.uncheckedEnqueue(learntClause[0], ClaRefUndef)
+			s.unitID[learntClause[0].Var()] = id
+			if confl := s.propagate(); confl != ClaRefUndef {
+				s.ok = false
+				if s.proof != nil {
+					s.proofConflict(confl)
+				}
+				return false
+			}
+			continue
+		}
+
+		//The binary implication tree of the root is built in the trail order. A literal implied by a longer clause
+		//is attached to the dominator of its implying literals, which is the closest common ancestor in the tree,
+		//and the hyper-binary resolvent (~dominator | q) becomes its reason.
+		//The literals implied by the units alone are not in the tree
+		parent, depth := map[Lit]Lit{}, map[Lit]int{p: 0}
+		dominator := func(a, b Lit) Lit {
+			for a != b {
+				if depth[a] < depth[b] {
+					a, b = b, a
+				}
+				a = parent[a]
+			}
+			return a
+		}
+		for _, q := range s.trail[s.trailLim[0]+1:] {
+			cr := s.reason(q.Var())
+			c := s.claAllocator.GetClause(cr)
+			dom := Lit{X: LitUndef}
+			for i := 1; i < c.Size(); i++ {
+				r := c.At(i)
+				r = r.Flip()
+				if _, inTree := depth[r]; !inTree {
+					continue
+				}
+				if dom.X == LitUndef {
+					dom = r
+				} else {
+					dom = dominator(dom, r)
+				}
+			}
+			if dom.X == LitUndef {
+				continue
+			}
+			if c.Size() > 2 {
+				subsumed := false
+				for i := 1; i < c.Size(); i++ {
+					subsumed = subsumed || c.At(i) == dom.Flip()
+				}
+				lits := []Lit{q, dom.Flip()}
+				id := s.newClauseID()
+				if s.proof != nil {
+					var hints []uint64
+					if s.proof.format == ProofLRAT {
+						//The reason of q is falsified by ~q and the implying literals are implied by dom
+						hints = s.lratHints(cr, lits)
+					}
+					s.proof.add(id, lits, hints)
+				}
+				claRef, err := s.claAllocator.NewAllocate(lits, true)
+				if err != nil {
+					panic(err)
+				}
+				s.claAllocator.GetClause(claRef).id = id
+				s.learnts = append(s.learnts, claRef)
+				if err := s.attachClause(claRef); err != nil {
+					panic(err)
+				}
+				s.varData[q.Var()].Reason = claRef
+				s.statistics.HyperBinaryCount++
+				if subsumed && c.Learnt() {
+					//The resolvent subsumes the learnt reason
+					s.removeClause(cr)
+				}
+			}
+			parent[q], depth[q] = dom, depth[dom]+1
+		}
+		s.cancelUntil(0)
+	}
+	return true
+}
+
+//probeRoots returns the roots of the binary implication graph in the order of the literals
+//A binary clause (a | b) gives the implications ~a -> b and ~b -> a, so p is a root if ~p is in a binary clause and p is not
+func (s *Solver) probeRoots() []Lit {
+	inBinary := make([]bool, 2*s.NumVars())
+	for _, refs := range [][]ClauseReference{s.clauses, s.learnts} {
+		for _, cr := range refs {
+			if c := s.claAllocator.GetClause(cr); c.Size() == 2 {
+				inBinary[c.At(0).X] = true
+				inBinary[c.At(1).X] = true
+			}
+		}
+	}
+	var roots []Lit
+	for x := range inBinary {
+		p := Lit{X: x}
+		if !inBinary[x] && inBinary[p.Flip().X] && !s.eliminated[p.Var()] {
+			roots = append(roots, p)
+		}
+	}
+	return roots
+}
+
+//liveClauses returns the clauses of the list which are not removed
+func (s *Solver) liveClauses(refs []ClauseReference) []ClauseReference {
+	j := 0
+	for _, cr := range refs {
+		if !s.claAllocator.Clauses[cr].IsRemoved() {
+			refs[j] = cr
+			j++
+		}
+	}
+	return refs[:j]
+}
+
+func containsLit(lits []Lit, p Lit) bool {
+	for _, q := range lits {
+		if q == p {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package gatosat
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/togatoga/gatosat/lrat"
+)
+
+func TestProbe(t *testing.T) {
+	s := NewSolver()
+	for v := 0; v < 5; v++ {
+		s.NewVar()
+	}
+	p, a, b, c, q := *NewLit(0, false), *NewLit(1, false), *NewLit(2, false), *NewLit(3, false), *NewLit(4, false)
+	//p implies a and b, which give c by a ternary clause, so (~p | c) is a hyper-binary resolvent.
+	//q implies a, b and ~c, so q fails
+	for _, lits := range [][]Lit{{p.Flip(), a}, {p.Flip(), b}, {a.Flip(), b.Flip(), c}, {q.Flip(), a}, {q.Flip(), b}, {q.Flip(), c.Flip()}} {
+		s.AddClause(lits)
+	}
+	if !s.probe() {
+		t.Fatal("The problem is unsatisfiable")
+	}
+	if s.valueLit(q) != LitBoolFalse {
+		t.Fatalf("The failed literal is not false: %v", s.valueLit(q))
+	}
+	found := false
+	for _, cr := range s.learnts {
+		cla := s.claAllocator.GetClause(cr)
+		found = found || cla.Size() == 2 && (cla.At(0) == p.Flip() && cla.At(1) == c || cla.At(0) == c && cla.At(1) == p.Flip())
+	}
+	if !found {
+		t.Fatal("The hyper-binary resolvent is not learnt")
+	}
+	if stats := s.Statistics(); stats.FailedLiteralCount != 1 || stats.ProbeCount != 1 {
+		t.Fatalf("The counters are wrong: %d failed %d probings", stats.FailedLiteralCount, stats.ProbeCount)
+	}
+}
+
+func TestProbeDominator(t *testing.T) {
+	s := NewSolver()
+	for v := 0; v < 5; v++ {
+		s.NewVar()
+	}
+	r, d, e, f, g := *NewLit(0, false), *NewLit(1, false), *NewLit(2, false), *NewLit(3, false), *NewLit(4, false)
+	//e and f are implied by d, so the hyper-binary resolvent of g is (~d | g) rather than (~r | g)
+	for _, lits := range [][]Lit{{r.Flip(), d}, {d.Flip(), e}, {d.Flip(), f}, {e.Flip(), f.Flip(), g}} {
+		s.AddClause(lits)
+	}
+	if !s.probe() {
+		t.Fatal("The problem is unsatisfiable")
+	}
+	var resolvents [][]Lit
+	for _, cr := range s.learnts {
+		cla := s.claAllocator.GetClause(cr)
+		resolvents = append(resolvents, append([]Lit(nil), cla.Data[:cla.Size()]...))
+	}
+	if len(resolvents) != 1 || !containsLit(resolvents[0], d.Flip()) || !containsLit(resolvents[0], g) {
+		t.Fatalf("The hyper-binary resolvent is not on the dominator: %v", resolvents)
+	}
+}
+
+func TestProbeWithPropagator(t *testing.T) {
+	s := NewSolver()
+	s.NewVar()
+	s.ConnectPropagator(&rejectingPropagator{})
+	s.statistics.ConflictCount = 10
+	if !s.probe() {
+		t.Fatal("The problem is unsatisfiable")
+	}
+	//The probing is skipped with a propagator, but the next one is still scheduled
+	if s.nextProbe != 10+probeInterval || s.statistics.ProbeCount != 0 {
+		t.Fatalf("The probing is scheduled at %d after %d probings", s.nextProbe, s.statistics.ProbeCount)
+	}
+}
+
+func TestProbeRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(4))
+	numVars := 14
+	var failed, resolvents uint64
+	for i := 0; i < 300; i++ {
+		clauses := randomCircuit(rnd, 6, 8, 1+rnd.Intn(6))
+		opts := DefaultOptions()
+		opts.Probing = true
+		s, err := NewSolverWithOptions(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var proof bytes.Buffer
+		s.SetClauseStore(NewMemoryClauseStore())
+		s.SetProof(&proof, ProofLRAT)
+		for v := 0; v < numVars; v++ {
+			s.NewVar()
+		}
+		for _, c := range clauses {
+			s.AddClause(append([]Lit(nil), c...))
+		}
+		status := s.Solve()
+		if expected := bruteForce(numVars, clauses, nil); status != expected {
+			t.Fatalf("The solver returns a wrong value: %v (expected %v) clauses %v", status, expected, clauses)
+		}
+		if status == LitBoolTrue {
+			if err := s.VerifyModel(); err != nil {
+				t.Fatalf("The model is wrong: %v", err)
+			}
+		} else {
+			s.FlushProof()
+			if err := lrat.NewChecker(dimacsClauses(clauses)).Check(&proof); err != nil {
+				t.Fatalf("The LRAT proof is not verified: %v", err)
+			}
+		}
+		if err := s.CheckInvariants(); err != nil {
+			t.Fatal(err)
+		}
+		failed += s.Statistics().FailedLiteralCount
+		resolvents += s.Statistics().HyperBinaryCount
+	}
+	if failed == 0 || resolvents == 0 {
+		t.Fatalf("No failed literal or hyper-binary resolvent is found: %d failed %d resolvents", failed, resolvents)
+	}
+}
@@ -0,0 +1,340 @@
+package gatosat
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+//ProofFormat is the format of the proof written by the solver
+type ProofFormat int
+
+const (
+	//ProofDRAT is the textual DRAT format
+	ProofDRAT ProofFormat = iota
+	//ProofBinaryDRAT is the binary DRAT format
+	ProofBinaryDRAT
+	//ProofLRAT is the textual LRAT format. Every added clause has its ID and the IDs of the clauses it is derived from
+	ProofLRAT
+)
+
+//proofWriter writes a proof in one of the proof formats
+type proofWriter struct {
+	w      *bufio.Writer
+	format ProofFormat
+	lastID uint64 //The ID of the last added clause
+	empty  bool   //The empty clause is already written
+	err    error  //The first error of the writer
+	buf    []byte
+
+	//The steps written outside of Solve while there are clauses derived outside of Solve. See newDerivedID
+	pending      []proofStep
+	numTemporary uint64 //The number of the temporary IDs
+}
+
+//proofStep is an addition or a deletion of a clause which is not written yet
+type proofStep struct {
+	deletion bool
+	id       uint64
+	lits     []Lit
+	hints    []uint64
+}
+
+//temporaryIDBase is the first temporary ID of the clauses derived outside of Solve
+const temporaryIDBase uint64 = 1 << 62
+
+func newProofWriter(w io.Writer, format ProofFormat) *proofWriter {
+	return &proofWriter{w: bufio.NewWriter(w), format: format}
+}
+
+//add writes the addition of the clause with the ID
+//hints are the IDs of the clauses from which the clause is derived by unit propagation. Only LRAT uses them
+func (p *proofWriter) add(id uint64, lits []Lit, hints []uint64) {
+	if len(lits) == 0 {
+		if p.empty {
+			return
+		}
+		p.empty = true
+	}
+	if id >= temporaryIDBase || len(p.pending) > 0 {
+		p.pending = append(p.pending, proofStep{id: id, lits: append([]Lit(nil), lits...), hints: append([]uint64(nil), hints...)})
+		return
+	}
+	p.writeAdd(id, lits, hints)
+}
+
+func (p *proofWriter) writeAdd(id uint64, lits []Lit, hints []uint64) {
+	p.lastID = id
+	buf := p.buf[:0]
+	switch p.format {
+	case ProofBinaryDRAT:
+		buf = appendBinaryLits(append(buf, 'a'), lits)
+	case ProofLRAT:
+		buf = strconv.AppendUint(buf, id, 10)
+		buf = appendTextLits(append(buf, ' '), lits)
+		for _, hint := range hints {
+			buf = strconv.AppendUint(append(buf, ' '), hint, 10)
+		}
+		buf = append(buf, " 0\n"...)
+	default:
+		buf = append(appendTextLits(buf, lits), '\n')
+	}
+	p.write(buf)
+}
+
+//delete writes the deletion of the clause with the ID
+func (p *proofWriter) delete(id uint64, lits []Lit) {
+	if len(p.pending) > 0 {
+		p.pending = append(p.pending, proofStep{deletion: true, id: id, lits: append([]Lit(nil), lits...)})
+		return
+	}
+	p.writeDelete(id, lits)
+}
+
+func (p *proofWriter) writeDelete(id uint64, lits []Lit) {
+	buf := p.buf[:0]
+	switch p.format {
+	case ProofBinaryDRAT:
+		buf = appendBinaryLits(append(buf, 'd'), lits)
+	case ProofLRAT:
+		buf = strconv.AppendUint(buf, p.lastID, 10)
+		buf = strconv.AppendUint(append(buf, " d "...), id, 10)
+		buf = append(buf, " 0\n"...)
+	default:
+		buf = append(appendTextLits(append(buf, "d "...), lits), '\n')
+	}
+	p.write(buf)
+}
+
+func (p *proofWriter) write(buf []byte) {
+	p.buf = buf
+	if p.err == nil {
+		_, p.err = p.w.Write(buf)
+	}
+}
+
+func (p *proofWriter) flush() error {
+	if p.err == nil {
+		p.err = p.w.Flush()
+	}
+	return p.err
+}
+
+//appendTextLits appends the literals terminated by 0
+func appendTextLits(buf []byte, lits []Lit) []byte {
+	for _, q := range lits {
+		buf = strconv.AppendInt(buf, int64(q.Dimacs()), 10)
+		buf = append(buf, ' ')
+	}
+	return append(buf, '0')
+}
+
+//appendBinaryLits appends the literals in the variable-length encoding of binary DRAT terminated by 0
+func appendBinaryLits(buf []byte, lits []Lit) []byte {
+	for _, q := range lits {
+		//The literal x is mapped to 2*|x| + (1 if x < 0)
+		u := 2 * uint32(q.Var()+1)
+		if q.Sign() {
+			u++
+		}
+		for u > 0x7f {
+			buf = append(buf, byte(u&0x7f|0x80))
+			u >>= 7
+		}
+		buf = append(buf, byte(u))
+	}
+	return append(buf, 0)
+}
+
+//SetProof starts writing a proof into w in the format
+//The proof logs every clause learnt or added during the search and every deleted clause,
+//and ends with the empty clause if the problem is unsatisfiable
+//SetProof should be called before any clause is added. The clauses added by AddClause get the IDs 1, 2, ...
+//in the order they are added, which matches the numbering of LRAT when they are read from a DIMACS file.
+//The clauses derived while adding clauses get their IDs after them when Solve is called
+//nil stops writing the proof
+func (s *Solver) SetProof(w io.Writer, format ProofFormat) {
+	if s.proof != nil {
+		s.proof.flush()
+	}
+	s.proof = nil
+	if w != nil {
+		s.proof = newProofWriter(w, format)
+	}
+}
+
+//FlushProof writes the buffered proof into the writer given to SetProof
+//It returns the first error that occurred while writing the proof
+func (s *Solver) FlushProof() error {
+	if s.proof == nil {
+		return nil
+	}
+	s.settleProof()
+	return s.proof.flush()
+}
+
+//newClauseID returns the ID for a new clause
+func (s *Solver) newClauseID() uint64 {
+	s.clauseIDCount++
+	return s.clauseIDCount
+}
+
+//newDerivedID returns the ID for a clause derived at level 0
+//Outside of Solve, the clause gets a temporary ID and its step is pending until settleProof,
+//so the clauses added by AddClause keep the consecutive IDs
+func (s *Solver) newDerivedID() uint64 {
+	if s.proof == nil || s.solving {
+		return s.newClauseID()
+	}
+	s.proof.numTemporary++
+	return temporaryIDBase + s.proof.numTemporary
+}
+
+//settleProof writes the pending steps of the proof and replaces the temporary IDs with new IDs
+//The added clauses with their own IDs are written first since they are not derived from any clause
+func (s *Solver) settleProof() {
+	if s.proof == nil || len(s.proof.pending) == 0 {
+		return
+	}
+	steps := s.proof.pending
+	s.proof.pending = nil
+	ids := map[uint64]uint64{}
+	settled := func(id uint64) uint64 {
+		if newID, ok := ids[id]; ok {
+			return newID
+		}
+		return id
+	}
+	for _, step := range steps {
+		if !step.deletion && step.id < temporaryIDBase {
+			s.proof.writeAdd(step.id, step.lits, step.hints)
+		}
+	}
+	for _, step := range steps {
+		switch {
+		case step.deletion:
+			s.proof.writeDelete(settled(step.id), step.lits)
+		case step.id >= temporaryIDBase:
+			ids[step.id] = s.newClauseID()
+			for i, hint := range step.hints {
+				step.hints[i] = settled(hint)
+			}
+			s.proof.writeAdd(ids[step.id], step.lits, step.hints)
+		}
+	}
+	for _, refs := range [][]ClauseReference{s.clauses, s.learnts} {
+		for _, cr := range refs {
+			c := s.claAllocator.GetClause(cr)
+			c.id = settled(c.id)
+		}
+	}
+	for x := range s.unitID {
+		s.unitID[x] = settled(s.unitID[x])
+	}
+	s.proof.numTemporary = 0
+}
+
+//proofUnit writes the unit clause of p propagated at level 0 and records its ID
+func (s *Solver) proofUnit(p Lit) {
+	if s.reason(p.Var()) == ClaRefUndef {
+		//The reason of the external propagator is a unit clause
+		return
+	}
+	c := s.claAllocator.GetClause(s.reason(p.Var()))
+	hints := make([]uint64, 0, c.Size())
+	for i := 0; i < c.Size(); i++ {
+		if q := c.At(i); q.Var() != p.Var() {
+			hints = append(hints, s.unitID[q.Var()])
+		}
+	}
+	id := s.newDerivedID()
+	s.unitID[p.Var()] = id
+	s.proof.add(id, []Lit{p}, append(hints, c.ID()))
+}
+
+//proofConflict writes the empty clause derived from the clause falsified at level 0
+func (s *Solver) proofConflict(confl ClauseReference) {
+	c := s.claAllocator.GetClause(confl)
+	hints := make([]uint64, 0, c.Size()+1)
+	for i := 0; i < c.Size(); i++ {
+		q := c.At(i)
+		hints = append(hints, s.unitID[q.Var()])
+	}
+	s.proof.add(s.newDerivedID(), nil, append(hints, c.ID()))
+}
+
+//proofTrimmed writes the clause trimmed by removing the literals false at level 0 and deletes the original
+//It returns the ID of the trimmed clause
+func (s *Solver) proofTrimmed(id uint64, trimmed, original []Lit) uint64 {
+	var hints []uint64
+	for i, q := range original {
+		if s.valueLit(q) == LitBoolFalse && !containsVar(original[:i], q.Var()) {
+			hints = append(hints, s.unitID[q.Var()])
+		}
+	}
+	newID := s.newDerivedID()
+	s.proof.add(newID, trimmed, append(hints, id))
+	s.proof.delete(id, original)
+	return newID
+}
+
+func containsVar(lits []Lit, x Var) bool {
+	for _, q := range lits {
+		if q.Var() == x {
+			return true
+		}
+	}
+	return false
+}
+
+//lratHints returns the IDs of the clauses from which the learnt clause is derived by unit propagation
+//It must be called before backtracking, while the literals of the learnt clause are false
+//The IDs of the unit clauses come first, then the reasons in the order of the trail and the conflicting clause at last
+func (s *Solver) lratHints(confl ClauseReference, learnt []Lit) []uint64 {
+	for _, q := range learnt {
+		s.seen[q.Var()] = true
+	}
+	var units, chain []uint64
+	unitAdded := map[Var]bool{}
+	needed := map[Var]bool{}
+	visit := func(c *Clause, skip Var) {
+		for i := 0; i < c.Size(); i++ {
+			x := c.Data[i].Var()
+			if x == skip || s.seen[x] {
+				continue
+			}
+			if s.level(x) == 0 {
+				if !unitAdded[x] {
+					unitAdded[x] = true
+					units = append(units, s.unitID[x])
+				}
+			} else {
+				needed[x] = true
+			}
+		}
+	}
+	conflCla := s.claAllocator.GetClause(confl)
+	visit(conflCla, VarUndef)
+	for i := len(s.trail) - 1; i >= 0 && len(needed) > 0; i-- {
+		x := s.trail[i].Var()
+		if !needed[x] {
+			continue
+		}
+		delete(needed, x)
+		if s.reason(x) == ClaRefUndef {
+			panic(fmt.Errorf("The variable implied by the learnt clause has no reason: %d", x))
+		}
+		c := s.claAllocator.GetClause(s.reason(x))
+		visit(c, x)
+		chain = append(chain, c.ID())
+	}
+	for _, q := range learnt {
+		s.seen[q.Var()] = false
+	}
+	hints := units
+	for i := len(chain) - 1; i >= 0; i-- {
+		hints = append(hints, chain[i])
+	}
+	return append(hints, conflCla.ID())
+}
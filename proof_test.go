@@ -0,0 +1,124 @@
+package gatosat
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/togatoga/gatosat/lrat"
+)
+
+//readTextProof returns the added clauses of a textual DRAT proof
+func readTextProof(t *testing.T, proof string) [][]Lit {
+	var lemmas [][]Lit
+	scanner := bufio.NewScanner(strings.NewReader(proof))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[len(fields)-1] != "0" {
+			t.Fatalf("The proof line is not terminated by 0: %q", scanner.Text())
+		}
+		if fields[0] == "d" {
+			continue
+		}
+		var lemma []Lit
+		for _, f := range fields[:len(fields)-1] {
+			x, err := strconv.Atoi(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lemma = append(lemma, *NewLitFromDimacs(x))
+		}
+		lemmas = append(lemmas, lemma)
+	}
+	return lemmas
+}
+
+func TestProof(t *testing.T) {
+	var text, binary bytes.Buffer
+	s := NewSolver()
+	s.SetProof(&text, ProofDRAT)
+	pigeonHole(s, 6, 5)
+	if status := s.Solve(); status != LitBoolFalse {
+		t.Fatalf("The solver returns a wrong value: %v", status)
+	}
+	if err := s.FlushProof(); err != nil {
+		t.Fatal(err)
+	}
+
+	//Every lemma must be implied by the problem and the previous lemmas
+	lemmas := readTextProof(t, text.String())
+	if len(lemmas) == 0 || len(lemmas[len(lemmas)-1]) != 0 {
+		t.Fatalf("The proof doesn't end with the empty clause")
+	}
+	checker := NewSolver()
+	pigeonHole(checker, 6, 5)
+	for _, lemma := range lemmas[:len(lemmas)-1] {
+		var assumptions []Lit
+		for _, p := range lemma {
+			assumptions = append(assumptions, p.Flip())
+		}
+		if status := checker.SolveWithAssumptions(assumptions); status != LitBoolFalse {
+			t.Fatalf("The lemma is not implied: %v", lemma)
+		}
+		checker.AddClause(lemma)
+	}
+
+	s = NewSolver()
+	s.SetProof(&binary, ProofBinaryDRAT)
+	pigeonHole(s, 6, 5)
+	s.Solve()
+	s.FlushProof()
+	if binary.Len() == 0 || binary.Bytes()[0] != 'a' || !bytes.HasSuffix(binary.Bytes(), []byte{'a', 0}) {
+		t.Fatalf("The binary proof is malformed")
+	}
+}
+
+//dimacsClauses returns the clauses as DIMACS literals
+func dimacsClauses(clauses [][]Lit) [][]int {
+	var xs [][]int
+	for _, c := range clauses {
+		var x []int
+		for _, p := range c {
+			x = append(x, p.Dimacs())
+		}
+		xs = append(xs, x)
+	}
+	return xs
+}
+
+func TestLRATProof(t *testing.T) {
+	_, clauses, proof := pigeonHoleProof(t, 6, 5, ProofLRAT)
+	checker := lrat.NewChecker(dimacsClauses(clauses))
+	if err := checker.Check(bytes.NewReader(proof)); err != nil {
+		t.Fatalf("The LRAT proof is not verified: %v", err)
+	}
+
+	//The clauses derived while adding the clauses must not shift the IDs of the clauses added after them
+	_, clauses, _ = pigeonHoleProof(t, 5, 4, ProofLRAT)
+	u := *NewLit(Var(20), false)
+	v := *NewLit(Var(21), false)
+	problem := [][]Lit{{u}, {u.Flip(), v, v}}
+	for _, c := range clauses {
+		problem = append(problem, append([]Lit{c[0], v.Flip()}, c...))
+	}
+	var buf bytes.Buffer
+	s := NewSolver()
+	s.SetProof(&buf, ProofLRAT)
+	for s.NumVars() < 22 {
+		s.NewVar()
+	}
+	for _, c := range problem {
+		s.AddClause(c)
+	}
+	if status := s.Solve(); status != LitBoolFalse {
+		t.Fatalf("The solver returns a wrong value: %v", status)
+	}
+	if err := s.FlushProof(); err != nil {
+		t.Fatal(err)
+	}
+	if err := lrat.NewChecker(dimacsClauses(problem)).Check(&buf); err != nil {
+		t.Fatalf("The LRAT proof is not verified: %v", err)
+	}
+}
@@ -0,0 +1,261 @@
+package gatosat
+
+import (
+	"fmt"
+	"sort"
+)
+
+//ExternalPropagator is the interface for domain-specific reasoning plugged into the search
+//The solver notifies the assignments of the observed variables and the backtracks,
+//and asks the propagator for implied literals, clauses and the approval of models
+type ExternalPropagator interface {
+	//NotifyAssignment is called when an observed variable is assigned
+	NotifyAssignment(p Lit)
+	//NotifyNewDecisionLevel is called when a new decision level is opened
+	NotifyNewDecisionLevel()
+	//NotifyBacktrack is called when the assignments above level are undone
+	NotifyBacktrack(level int)
+	//Propagate returns literals implied by the current assignment
+	//It is called whenever the unit propagation reaches a fixpoint
+	Propagate() []Lit
+	//Reason returns the reason clause of a literal returned by Propagate
+	//The first literal must be p and the other literals must be false
+	//The solver asks for the reason lazily, only when it is needed for the conflict analysis
+	Reason(p Lit) []Lit
+	//CheckModel is called with a complete assignment before it is reported as a model
+	//If it returns false, the propagator must provide a clause falsified by the assignment through ExternalClause
+	//Otherwise the search stops and Solve returns LitBoolUndef
+	CheckModel(model []LitBool) bool
+	//HasExternalClause returns true if the propagator has a clause to add
+	HasExternalClause() bool
+	//ExternalClause returns the next clause to add
+	//The clause must be implied by the problem and the domain of the propagator
+	ExternalClause() []Lit
+}
+
+//claRefLazy is the reason of a literal propagated by the external propagator whose reason clause is not asked yet
+const claRefLazy ClauseReference = ClaRefUndef - 1
+
+//ConnectPropagator connects the external propagator to the solver
+//Only the assignments of the variables added by AddObservedVar are notified
+func (s *Solver) ConnectPropagator(propagator ExternalPropagator) {
+	if s.propagator != nil {
+		panic(fmt.Errorf("An external propagator is already connected"))
+	}
+	s.propagator = propagator
+}
+
+//DisconnectPropagator disconnects the external propagator
+//The reasons of the literals fixed by the propagator are asked before it is disconnected
+func (s *Solver) DisconnectPropagator() {
+	if s.propagator == nil {
+		return
+	}
+	s.materializeLazyReasons()
+	s.propagator = nil
+	for v := range s.observed {
+		s.observed[v] = false
+	}
+}
+
+//AddObservedVar makes the assignments of the variable notified to the external propagator
+//An assignment at level 0 is notified immediately
+func (s *Solver) AddObservedVar(v Var) {
+	if s.propagator == nil {
+		panic(fmt.Errorf("No external propagator is connected"))
+	}
+	if s.observed[v] {
+		return
+	}
+	s.restoreEliminated([]Lit{*NewLit(v, false)})
+	s.observed[v] = true
+	if s.valueVar(v) != LitBoolUndef {
+		s.propagator.NotifyAssignment(*NewLit(v, s.valueVar(v) == LitBoolFalse))
+	}
+}
+
+//materializeLazyReasons asks the reasons of all literals propagated by the external propagator in the trail
+func (s *Solver) materializeLazyReasons() {
+	for _, p := range s.trail {
+		if s.varData[p.Var()].Reason == claRefLazy {
+			s.materializeReason(p.Var())
+		}
+	}
+}
+
+//materializeReason asks the external propagator for the reason of the variable and adds it as a learnt clause
+func (s *Solver) materializeReason(x Var) {
+	p := *NewLit(x, s.valueVar(x) == LitBoolFalse)
+	lits := append([]Lit(nil), s.propagator.Reason(p)...)
+	if len(lits) == 0 || lits[0] != p {
+		panic(fmt.Errorf("The reason clause doesn't start with the propagated literal %v: %v", p, lits))
+	}
+	for _, q := range lits[1:] {
+		if s.valueLit(q) != LitBoolFalse {
+			panic(fmt.Errorf("The reason clause of %v has a literal which is not false: %v", p, q))
+		}
+	}
+	id := s.newClauseID()
+	if s.proof != nil {
+		s.proof.add(id, lits, nil)
+	}
+	if len(lits) == 1 {
+		s.varData[x].Reason = ClaRefUndef
+		if s.level(x) == 0 {
+			s.unitID[x] = id
+		}
+		return
+	}
+	//Watch the false literal assigned last
+	sort.SliceStable(lits[1:], func(i, j int) bool {
+		return s.level(lits[i+1].Var()) > s.level(lits[j+1].Var())
+	})
+	cr, err := s.claAllocator.NewAllocate(lits, true)
+	if err != nil {
+		panic(err)
+	}
+	s.claAllocator.GetClause(cr).id = id
+	s.learnts = append(s.learnts, cr)
+	if err := s.attachClause(cr); err != nil {
+		panic(err)
+	}
+	s.varData[x].Reason = cr
+}
+
+//externalPropagate pulls the clauses and the implied literals from the external propagator
+//until it has nothing more. It returns a conflicting clause if it finds a conflict
+func (s *Solver) externalPropagate() ClauseReference {
+	for {
+		for s.propagator.HasExternalClause() {
+			confl := s.addExternalClause(s.propagator.ExternalClause(), false)
+			if !s.ok || confl != ClaRefUndef {
+				return confl
+			}
+			if confl = s.propagate(); confl != ClaRefUndef {
+				return confl
+			}
+		}
+
+		assigned := false
+		for _, p := range s.propagator.Propagate() {
+			switch s.valueLit(p) {
+			case LitBoolUndef:
+				s.uncheckedEnqueue(p, claRefLazy)
+				assigned = true
+			case LitBoolFalse:
+				return s.addExternalClause(s.propagator.Reason(p), true)
+			}
+		}
+		if !assigned {
+			return ClaRefUndef
+		}
+		if confl := s.propagate(); confl != ClaRefUndef {
+			return confl
+		}
+	}
+}
+
+//addExternalClause adds a clause during the search
+//It backtracks if the clause is unit or conflicting at a lower level than the current one
+//and returns the clause if it is conflicting at the (new) current level
+func (s *Solver) addExternalClause(lits []Lit, learnt bool) ClauseReference {
+	ps := append([]Lit(nil), lits...)
+	sort.Slice(ps, func(i, j int) bool {
+		return ps[i].X < ps[j].X
+	})
+	//Remove duplicates and the literals false at level 0
+	p := Lit{X: LitUndef}
+	copiedIdx := 0
+	for i := 0; i < len(ps); i++ {
+		q := ps[i]
+		if q.Equal(p.Flip()) || (s.valueLit(q) == LitBoolTrue && s.level(q.Var()) == 0) {
+			return ClaRefUndef
+		}
+		if (s.valueLit(q) != LitBoolFalse || s.level(q.Var()) > 0) && q.NotEqual(p) {
+			ps[copiedIdx], p = q, q
+			copiedIdx++
+		}
+	}
+	ps = ps[:copiedIdx]
+	//The clauses from outside are not derived from the clauses of the solver, so they have no hints
+	id := s.newClauseID()
+	if s.proof != nil {
+		s.proof.add(id, ps, nil)
+	}
+	if len(ps) == 0 {
+		s.ok = false
+		return ClaRefUndef
+	}
+	if len(ps) == 1 {
+		s.cancelUntil(0)
+		s.uncheckedEnqueue(ps[0], ClaRefUndef)
+		s.unitID[ps[0].Var()] = id
+		return ClaRefUndef
+	}
+
+	//Watch the true literals first, then the unassigned ones, then the false ones assigned last
+	rank := func(q Lit) int {
+		switch s.valueLit(q) {
+		case LitBoolTrue:
+			return 2
+		case LitBoolUndef:
+			return 1
+		}
+		return 0
+	}
+	sort.SliceStable(ps, func(i, j int) bool {
+		ri, rj := rank(ps[i]), rank(ps[j])
+		if ri != rj {
+			return ri > rj
+		}
+		if ri == 2 {
+			return s.level(ps[i].Var()) < s.level(ps[j].Var())
+		}
+		return ri == 0 && s.level(ps[i].Var()) > s.level(ps[j].Var())
+	})
+
+	cr, err := s.claAllocator.NewAllocate(ps, learnt)
+	if err != nil {
+		panic(err)
+	}
+	s.claAllocator.GetClause(cr).id = id
+	if learnt {
+		s.learnts = append(s.learnts, cr)
+	} else {
+		s.clauses = append(s.clauses, cr)
+	}
+	if err := s.attachClause(cr); err != nil {
+		panic(err)
+	}
+
+	first, second := ps[0], ps[1]
+	if s.valueLit(second) != LitBoolFalse {
+		return ClaRefUndef
+	}
+	switch s.valueLit(first) {
+	case LitBoolTrue:
+		//The clause should have propagated the first literal at a lower level
+		if s.level(first.Var()) > s.level(second.Var()) {
+			s.cancelUntil(s.level(second.Var()))
+			s.uncheckedEnqueue(first, cr)
+		}
+	case LitBoolUndef:
+		s.cancelUntil(s.level(second.Var()))
+		s.uncheckedEnqueue(first, cr)
+	case LitBoolFalse:
+		if s.level(first.Var()) == s.level(second.Var()) {
+			s.cancelUntil(s.level(first.Var()))
+			return cr
+		}
+		s.cancelUntil(s.level(second.Var()))
+		s.uncheckedEnqueue(first, cr)
+	}
+	return ClaRefUndef
+}
+
+//checkExternalModel asks the external propagator to approve the current complete assignment
+func (s *Solver) checkExternalModel() bool {
+	model := make([]LitBool, len(s.assigns))
+	copy(model, s.assigns)
+	return s.propagator.CheckModel(model)
+}
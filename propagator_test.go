@@ -0,0 +1,193 @@
+package gatosat
+
+import "testing"
+
+//atMostOnePropagator enforces that at most one variable of each group is true
+type atMostOnePropagator struct {
+	groups      [][]Var
+	groupOf     map[Var]int
+	trueVar     map[int]Var // The true variable of each group
+	trail       [][]Var     // The assigned variables of each decision level
+	pending     []Lit
+	reasons     map[Lit][]Lit
+	clauses     [][]Lit
+	checkOnly   bool // Enforce the constraint only by rejecting models
+	assignments int
+}
+
+func newAtMostOnePropagator(s *Solver, groups [][]Var, checkOnly bool) *atMostOnePropagator {
+	p := &atMostOnePropagator{groups: groups, groupOf: map[Var]int{}, trueVar: map[int]Var{}, trail: [][]Var{nil}, reasons: map[Lit][]Lit{}, checkOnly: checkOnly}
+	s.ConnectPropagator(p)
+	for g, vars := range groups {
+		for _, v := range vars {
+			p.groupOf[v] = g
+			s.AddObservedVar(v)
+		}
+	}
+	return p
+}
+
+func (p *atMostOnePropagator) NotifyAssignment(lit Lit) {
+	p.assignments++
+	if lit.Sign() {
+		return
+	}
+	g := p.groupOf[lit.Var()]
+	if _, ok := p.trueVar[g]; ok {
+		return
+	}
+	p.trueVar[g] = lit.Var()
+	p.trail[len(p.trail)-1] = append(p.trail[len(p.trail)-1], lit.Var())
+	if p.checkOnly {
+		return
+	}
+	for _, v := range p.groups[g] {
+		if v != lit.Var() {
+			q := *NewLit(v, true)
+			p.pending = append(p.pending, q)
+			p.reasons[q] = []Lit{q, *NewLit(lit.Var(), true)}
+		}
+	}
+}
+
+func (p *atMostOnePropagator) NotifyNewDecisionLevel() {
+	p.trail = append(p.trail, nil)
+}
+
+func (p *atMostOnePropagator) NotifyBacktrack(level int) {
+	for len(p.trail) > level+1 {
+		for _, v := range p.trail[len(p.trail)-1] {
+			delete(p.trueVar, p.groupOf[v])
+		}
+		p.trail = p.trail[:len(p.trail)-1]
+	}
+	p.pending = p.pending[:0]
+}
+
+func (p *atMostOnePropagator) Propagate() []Lit {
+	lits := p.pending
+	p.pending = nil
+	return lits
+}
+
+func (p *atMostOnePropagator) Reason(lit Lit) []Lit {
+	return p.reasons[lit]
+}
+
+func (p *atMostOnePropagator) CheckModel(model []LitBool) bool {
+	for _, vars := range p.groups {
+		var trueVars []Var
+		for _, v := range vars {
+			if model[v] == LitBoolTrue {
+				trueVars = append(trueVars, v)
+			}
+		}
+		if len(trueVars) > 1 {
+			p.clauses = append(p.clauses, []Lit{*NewLit(trueVars[0], true), *NewLit(trueVars[1], true)})
+			return false
+		}
+	}
+	return true
+}
+
+func (p *atMostOnePropagator) HasExternalClause() bool {
+	return len(p.clauses) > 0
+}
+
+func (p *atMostOnePropagator) ExternalClause() []Lit {
+	c := p.clauses[0]
+	p.clauses = p.clauses[1:]
+	return c
+}
+
+//pigeonHoleWithPropagator encodes that every pigeon is in a hole by clauses
+//and that every hole has at most one pigeon by the propagator
+func pigeonHoleWithPropagator(pigeons, holes int, checkOnly bool) (*Solver, *atMostOnePropagator) {
+	s := NewSolver()
+	x := make([][]Var, pigeons)
+	for i := range x {
+		x[i] = make([]Var, holes)
+		var c []Lit
+		for j := range x[i] {
+			x[i][j] = s.NewVar()
+			c = append(c, *NewLit(x[i][j], false))
+		}
+		s.AddClause(c)
+	}
+	groups := make([][]Var, holes)
+	for j := range groups {
+		for i := 0; i < pigeons; i++ {
+			groups[j] = append(groups[j], x[i][j])
+		}
+	}
+	return s, newAtMostOnePropagator(s, groups, checkOnly)
+}
+
+func TestExternalPropagator(t *testing.T) {
+	for _, checkOnly := range []bool{false, true} {
+		s, p := pigeonHoleWithPropagator(5, 5, checkOnly)
+		if status := s.Solve(); status != LitBoolTrue {
+			t.Fatalf("The solver returns a wrong value for a sat problem: %v", status)
+		}
+		if !p.CheckModel(s.Model()) || p.assignments == 0 {
+			t.Fatalf("The model violates the propagator: %v", s.Model())
+		}
+
+		s, _ = pigeonHoleWithPropagator(6, 5, checkOnly)
+		if status := s.Solve(); status != LitBoolFalse {
+			t.Fatalf("The solver returns a wrong value for an unsat problem: %v", status)
+		}
+	}
+}
+
+func TestExternalPropagatorIncremental(t *testing.T) {
+	s, p := pigeonHoleWithPropagator(5, 5, false)
+	//Pigeon 0 is in hole 0 and pigeon 1 is in hole 0
+	assumptions := []Lit{*NewLit(Var(0), false), *NewLit(Var(5), false)}
+	if status := s.SolveWithAssumptions(assumptions); status != LitBoolFalse {
+		t.Fatalf("The solver returns a wrong value under assumptions: %v", status)
+	}
+	if len(s.FailedAssumptions()) != 2 {
+		t.Fatalf("The failed assumptions are wrong: %v", s.FailedAssumptions())
+	}
+	if status := s.SolveWithAssumptions(assumptions[:1]); status != LitBoolTrue || !p.CheckModel(s.Model()) {
+		t.Fatalf("The solver returns a wrong value under an assumption: %v", status)
+	}
+	c := s.Clone()
+	s.DisconnectPropagator()
+	if status := c.Solve(); status != LitBoolTrue {
+		t.Fatalf("The copy returns a wrong value: %v", status)
+	}
+}
+
+//rejectingPropagator rejects every model without a clause
+type rejectingPropagator struct {
+	checks int
+}
+
+func (p *rejectingPropagator) NotifyAssignment(lit Lit)        {}
+func (p *rejectingPropagator) NotifyNewDecisionLevel()         {}
+func (p *rejectingPropagator) NotifyBacktrack(level int)       {}
+func (p *rejectingPropagator) Propagate() []Lit                { return nil }
+func (p *rejectingPropagator) Reason(lit Lit) []Lit            { return nil }
+func (p *rejectingPropagator) HasExternalClause() bool         { return false }
+func (p *rejectingPropagator) ExternalClause() []Lit           { return nil }
+func (p *rejectingPropagator) CheckModel(model []LitBool) bool { p.checks++; return false }
+
+func TestExternalPropagatorRejectsModel(t *testing.T) {
+	s := NewSolver()
+	for i := 0; i < 3; i++ {
+		s.NewVar()
+	}
+	s.AddClause([]Lit{*NewLit(Var(0), false), *NewLit(Var(1), true)})
+	p := &rejectingPropagator{}
+	s.ConnectPropagator(p)
+	if status := s.Solve(); status != LitBoolUndef || p.checks != 1 {
+		t.Fatalf("The search doesn't stop when the model is rejected: %v %d", status, p.checks)
+	}
+	//The solver stays usable
+	s.DisconnectPropagator()
+	if status := s.Solve(); status != LitBoolTrue {
+		t.Fatalf("The solver returns a wrong value after the rejection: %v", status)
+	}
+}
@@ -0,0 +1,38 @@
+package gatosat
+
+import "fmt"
+
+//Push opens a new scope
+//Clauses added until the matching Pop are retracted by the Pop
+//A scope is implemented by an activation variable which is assumed in every call to Solve
+//and appended negatively to every clause added inside the scope
+func (s *Solver) Push() {
+	v := s.NewVar()
+	s.SetDecisionVar(v, false)
+	s.activation[v] = true
+	s.scopes = append(s.scopes, *NewLit(v, false))
+}
+
+//Pop closes the last scope opened by Push and retracts the clauses added inside it
+//The learnt clauses depending on the retracted clauses are removed as well
+func (s *Solver) Pop() error {
+	if len(s.scopes) == 0 {
+		return fmt.Errorf("There is no scope to pop")
+	}
+	p := s.scopes[len(s.scopes)-1]
+	s.scopes = s.scopes[:len(s.scopes)-1]
+	//The unit clause satisfies every clause of the scope and every learnt clause derived from them
+	id := s.newClauseID()
+	if s.proof != nil {
+		s.proof.add(id, []Lit{p.Flip()}, nil)
+	}
+	if s.addClauseWithID(id, []Lit{p.Flip()}) {
+		s.simplify()
+	}
+	return nil
+}
+
+//NumScopes returns the number of the scopes opened by Push
+func (s *Solver) NumScopes() int {
+	return len(s.scopes)
+}
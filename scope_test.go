@@ -0,0 +1,61 @@
+package gatosat
+
+import "testing"
+
+func TestPushPop(t *testing.T) {
+	s := NewSolver()
+	x := s.NewVar()
+	y := s.NewVar()
+	s.AddClause([]Lit{*NewLit(x, false), *NewLit(y, false)})
+	numClauses := s.NumClauses()
+
+	s.Push()
+	s.AddClause([]Lit{*NewLit(x, true)})
+	if status := s.Solve(); status != LitBoolTrue || s.Value(x) != LitBoolFalse {
+		t.Fatalf("The solver returns a wrong value in the first scope: %v %v", status, s.Model())
+	}
+	s.Push()
+	s.AddClause([]Lit{*NewLit(y, true)})
+	if status := s.SolveWithAssumptions([]Lit{*NewLit(x, true)}); status != LitBoolFalse {
+		t.Fatalf("The solver returns a wrong value in the second scope: %v", status)
+	}
+	if failed := s.FailedAssumptions(); len(failed) > 1 {
+		t.Fatalf("The failed assumptions contain activation literals: %v", failed)
+	}
+	if err := s.Pop(); err != nil {
+		t.Fatal(err)
+	}
+	if status := s.Solve(); status != LitBoolTrue || s.Value(x) != LitBoolFalse || s.Value(y) != LitBoolTrue {
+		t.Fatalf("The solver returns a wrong value after the first pop: %v %v", status, s.Model())
+	}
+	if err := s.Pop(); err != nil {
+		t.Fatal(err)
+	}
+	if status := s.SolveWithAssumptions([]Lit{*NewLit(y, true)}); status != LitBoolTrue || s.Value(x) != LitBoolTrue {
+		t.Fatalf("The solver returns a wrong value after the second pop: %v %v", status, s.Model())
+	}
+	if s.NumClauses() != numClauses || len(s.learnts) != 0 || s.NumScopes() != 0 {
+		t.Fatalf("The scoped clauses are not retracted: %d clauses %d learnts", s.NumClauses(), len(s.learnts))
+	}
+	if err := s.Pop(); err == nil {
+		t.Fatal("Pop without Push succeeds")
+	}
+}
+
+func TestPushPopPigeonHole(t *testing.T) {
+	s := NewSolver()
+	s.Push()
+	pigeonHole(s, 6, 5)
+	if status := s.Solve(); status != LitBoolFalse {
+		t.Fatalf("The solver returns a wrong value in the scope: %v", status)
+	}
+	if err := s.Pop(); err != nil {
+		t.Fatal(err)
+	}
+	if status := s.Solve(); status != LitBoolTrue {
+		t.Fatalf("The solver returns a wrong value after the pop: %v", status)
+	}
+	if s.NumClauses() != 0 || len(s.learnts) != 0 {
+		t.Fatalf("The scoped clauses are not retracted: %d clauses %d learnts", s.NumClauses(), len(s.learnts))
+	}
+}
@@ -1,6 +1,7 @@
-package main
+package gatosat
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"sort"
@@ -11,218 +12,286 @@ import (
 
 //Solver is the structure for a solver and has much information to solve a sat problem
 type Solver struct {
-	Verbosity                  bool
-	ClaAllocator               *ClauseAllocator  //The allocator for clause
-	Clauses                    []ClauseReference //List of problem clauses.
-	LearntClauses              []ClauseReference //List of learnt clauses.
-	Watches                    *Watches          //'watches[lit]' is a list of constraints watching 'lit' (will go there if literal becomes true).
-	Assigns                    []LitBool         //The current assignments.
-	Polarity                   []LitBool         //The preferred polarity of each variable.
-	Qhead                      int               //Head of queue (as index into the trail -- no more explicit propagation queue in MiniSat).
-	Trail                      []Lit             //Assignment stack; stores all assigments made in the order the were made.
-	TrailLim                   []int             //Separator indices for different decision levels in 'trail'.
-	NextVar                    Var               //Next variable to be created.
-	Decision                   []bool            // A priority queue of variables ordered with respect to the variable activity.
-	VarData                    []VarData         //Stores reason and level for each variable.
-	VarOrder                   *Heap             // A priority queue of variables ordered with respect to the variable activity.
-	OK                         bool              //If FALSE, the constraints are already unsatisfiable. No part of the solver state may be used!
-	RestartFirst               int               // The initial restart limit.
-	RestartIncreaseRatio       float64           // The factor with which the restart limit is multiplied in each restart.                    (default 1.5)
-	VarIncreaseRatio           float64           // Amount to bump next variable with.
-	VarDecayRatio              float64           //
-	ClauseActitvyIncreaseRatio float32           // Amount to bump next clause with
-	ClauseActitvyDecayRatio    float32           //
-	MaxNumLearnt               float64           //
-	LearntSizeAdjustConflict   float64           //
-	Seen                       []bool            //The seen variable for clause learning
-	Model                      []LitBool         // If problem is satisfiable, this vector contains the model (if any).
-	Statistics                 *Statistics       //Statistics
-}
-
-//NewSolver returns a pointer of Solver and initializes variables and sets paramters
+	opts                        SolverOptions      //The parameters of the solver
+	randomSeed                  float64            //The state of the random variable selection
+	claAllocator                *ClauseAllocator   //The allocator for clause
+	clauses                     []ClauseReference  //List of problem clauses.
+	learnts                     []ClauseReference  //List of learnt clauses.
+	watches                     *Watches           //'watches[lit]' is a list of constraints watching 'lit' (will go there if literal becomes true).
+	assigns                     []LitBool          //The current assignments.
+	polarity                    []LitBool          //The preferred polarity of each variable.
+	userPolarity                []LitBool          //The polarity of each variable given by SetPolarity. LitBoolUndef uses the preferred polarity.
+	qhead                       int                //Head of queue (as index into the trail -- no more explicit propagation queue in MiniSat).
+	trail                       []Lit              //Assignment stack; stores all assigments made in the order the were made.
+	trailLim                    []int              //Separator indices for different decision levels in 'trail'.
+	nextVar                     Var                //Next variable to be created.
+	decision                    []bool             // A priority queue of variables ordered with respect to the variable activity.
+	varData                     []VarData          //Stores reason and level for each variable.
+	varOrder                    *Heap              // A priority queue of variables ordered with respect to the variable activity.
+	ok                          bool               //If FALSE, the constraints are already unsatisfiable. No part of the solver state may be used!
+	varIncreaseRatio            float64            // Amount to bump next variable with.
+	clauseActivityIncreaseRatio float32            // Amount to bump next clause with
+	maxNumLearnt                float64            //
+	learntSizeAdjustConflict    float64            //
+	nextInvariantCheck          uint64             //The number of the conflicts at which the invariants are checked next
+	seen                        []bool             //The seen variable for clause learning
+	model                       []LitBool          // If problem is satisfiable, this vector contains the model (if any).
+	assumptions                 []Lit              //Current set of assumptions provided to solve by the user.
+	conflict                    []Lit              //If problem is unsatisfiable (possibly under assumptions), this vector represent the final conflict clause expressed in the assumptions.
+	scopes                      []Lit              //The activation literals of the scopes opened by Push. They are assumed in every call to Solve.
+	activation                  []bool             //'activation[v]' is true if v is an activation variable of a scope.
+	propagator                  ExternalPropagator //The external propagator connected by ConnectPropagator.
+	observed                    []bool             //'observed[v]' is true if the assignments of v are notified to the external propagator.
+	modelRejected               bool               //Set when the external propagator rejects a model without a clause. The search stops.
+	conflictBudget              int64              //-1 means no budget.
+	propagationBudget           int64              //-1 means no budget.
+	asyncInterrupt              int32              //Set to 1 by Interrupt or a cancelled context. Accessed atomically.
+	terminate                   func() bool        //Polled during the search. The search stops if it returns true.
+	learn                       func([]Lit, int)   //Called with every learnt clause whose size is at most learnMaxLength.
+	learnMaxLength              int                //
+	importClauses               func() [][]Lit     //Called at every restart. The returned clauses are added as learnt clauses.
+	lbdStamp                    uint64             //The stamp for computing LBD
+	lbdLevels                   []uint64           //The stamp of each decision level for computing LBD
+	proof                       *proofWriter       //The proof writer. nil if no proof is written.
+	clauseIDCount               uint64             //The ID of the last clause
+	solving                     bool               //Solve is running
+	unitID                      []uint64           //The ID of the unit clause of each variable assigned at level 0
+	clauseStore                 ClauseStore        //The copy of the clauses added by AddClause. nil if no copy is kept.
+	clauseStoreErr              error              //The first error of the clause store
+	frozen                      []bool             //'frozen[v]' is true if v is never eliminated.
+	eliminated                  []bool             //'eliminated[v]' is true if v is eliminated by the variable elimination.
+	elimStack                   []elimEntry        //The clauses removed by the variable elimination in the order of the removal.
+	elimDone                    bool               //The variable elimination has already run.
+	witness                     []bool             //'witness[v]' is true if the extension of the model may flip v for a blocked or covered clause.
+	blockedDone                 bool               //The blocked and covered clause elimination has already run.
+	nextSubsumption             uint64             //The number of the conflicts at which the subsumption runs next
+	probeNext                   int                //The probing starts from the first root whose X is at least probeNext.
+	nextProbe                   uint64             //The number of the conflicts at which the probing runs next
+	probeSearchPropagations     uint64             //The number of the propagations of the search at the last probing
+	statistics                  *Statistics        //Statistics
+}
+
+//NewSolver returns a pointer of Solver with the default options
 func NewSolver() *Solver {
-	return &Solver{
-		Verbosity:                  *Verbose,
-		ClaAllocator:               NewClauseAllocator(),
-		Watches:                    NewWatches(),
-		Qhead:                      0,
-		NextVar:                    0,
-		VarOrder:                   NewHeap(),
-		OK:                         true,
-		RestartFirst:               100,
-		RestartIncreaseRatio:       2,
-		VarIncreaseRatio:           1.0,
-		VarDecayRatio:              0.95,
-		ClauseActitvyIncreaseRatio: 1.0,
-		ClauseActitvyDecayRatio:    0.999,
-		MaxNumLearnt:               100,
-		LearntSizeAdjustConflict:   100,
-		Statistics:                 NewStatistics(),
+	s, err := NewSolverWithOptions(DefaultOptions())
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+//NewSolverWithOptions returns a pointer of Solver and initializes variables and sets paramters
+func NewSolverWithOptions(opts SolverOptions) (*Solver, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
 	}
+	return &Solver{
+		opts:                        opts,
+		randomSeed:                  opts.Seed,
+		claAllocator:                NewClauseAllocator(),
+		watches:                     NewWatches(),
+		qhead:                       0,
+		nextVar:                     0,
+		varOrder:                    NewHeap(),
+		ok:                          true,
+		varIncreaseRatio:            1.0,
+		clauseActivityIncreaseRatio: 1.0,
+		maxNumLearnt:                100,
+		learntSizeAdjustConflict:    opts.LearntSizeAdjustStart,
+		conflictBudget:              -1,
+		propagationBudget:           -1,
+		statistics:                  NewStatistics(),
+	}, nil
+}
+
+//Options returns the options of the solver
+func (s *Solver) Options() SolverOptions {
+	return s.opts
 }
 
 //NewVar create a new var
 func (s *Solver) NewVar() Var {
-	v := s.NextVar
-	s.NextVar++
-	s.Watches.Init(v)
-	s.Assigns = append(s.Assigns, LitBoolUndef)
-	s.Polarity = append(s.Polarity, LitBoolFalse)
-	s.VarData = append(s.VarData, *NewVarData(ClaRefUndef, 0))
-	s.Seen = append(s.Seen, false)
-	s.Decision = append(s.Decision, true)
+	v := s.nextVar
+	s.nextVar++
+	s.watches.Init(v)
+	s.assigns = append(s.assigns, LitBoolUndef)
+	s.polarity = append(s.polarity, LitBoolFalse)
+	s.userPolarity = append(s.userPolarity, LitBoolUndef)
+	s.varData = append(s.varData, *NewVarData(ClaRefUndef, 0))
+	s.seen = append(s.seen, false)
+	s.decision = append(s.decision, true)
+	s.activation = append(s.activation, false)
+	s.observed = append(s.observed, false)
+	s.unitID = append(s.unitID, 0)
+	s.frozen = append(s.frozen, false)
+	s.eliminated = append(s.eliminated, false)
+	s.witness = append(s.witness, false)
 	s.SetDecisionVar(v, true)
 	return v
 }
 
 func (s *Solver) varDecayActivity() {
-	s.VarIncreaseRatio *= (1 / s.VarDecayRatio)
+	s.varIncreaseRatio *= (1 / s.opts.VarDecayRatio)
 }
 
 func (s *Solver) varBumpActitivy(v Var) {
-	s.varBumpActitivyByInc(v, s.VarIncreaseRatio)
+	s.varBumpActitivyByInc(v, s.varIncreaseRatio)
 }
 
 func (s *Solver) clauseDecayActivity() {
-	s.ClauseActitvyIncreaseRatio *= (1 / s.ClauseActitvyDecayRatio)
+	s.clauseActivityIncreaseRatio *= float32(1 / s.opts.ClauseDecayRatio)
 }
 
 func (s *Solver) clauseBumpActivity(c *Clause) {
-	c.Act += s.ClauseActitvyIncreaseRatio
+	c.Act += s.clauseActivityIncreaseRatio
 	if c.Activity() > 1e20 {
 		//Rescale:
-		for _, claRef := range s.LearntClauses {
-			c := s.ClaAllocator.GetClause(claRef)
+		for _, claRef := range s.learnts {
+			c := s.claAllocator.GetClause(claRef)
 			c.Act *= 1e-20
 		}
-		s.ClauseActitvyIncreaseRatio *= 1e-20
+		s.clauseActivityIncreaseRatio *= 1e-20
 	}
 }
 
 func (s *Solver) varBumpActitivyByInc(v Var, inc float64) {
-	s.VarOrder.activity[v] += inc
-	if s.VarOrder.Activity(v) > 1e100 {
+	s.varOrder.activity[v] += inc
+	if s.varOrder.Activity(v) > 1e100 {
 		//Rscale:
 		for i := 0; i < s.NumVars(); i++ {
-			s.VarOrder.activity[i] *= 1e-100
+			s.varOrder.activity[i] *= 1e-100
 		}
-		s.VarIncreaseRatio *= 1e-100
+		s.varIncreaseRatio *= 1e-100
 	}
 	// Update order_heap with respect to new activity:
-	if s.VarOrder.InHeap(v) {
-		s.VarOrder.Decrease(v)
+	if s.varOrder.InHeap(v) {
+		s.varOrder.Decrease(v)
 	}
 }
 
 // NumVars returns the number of variables
 func (s *Solver) NumVars() int {
-	return int(s.NextVar)
+	return int(s.nextVar)
 }
 
 // NumAssigns returns the number what solver assigned literal
 func (s *Solver) NumAssigns() int {
-	return len(s.Trail)
+	return len(s.trail)
 }
 
 // UncheckedEnqueue assigns a value to true and reason if the value has
-func (s *Solver) UncheckedEnqueue(p Lit, from ClauseReference) {
-	if s.ValueLit(p) != LitBoolUndef {
-		panic(fmt.Sprintf("The assign is not LiteralUndef: ValueLit(%d) = %v", p, s.ValueLit(p)))
+func (s *Solver) uncheckedEnqueue(p Lit, from ClauseReference) {
+	if s.valueLit(p) != LitBoolUndef {
+		panic(fmt.Sprintf("The assign is not LiteralUndef: ValueLit(%d) = %v", p, s.valueLit(p)))
 	}
 	if !p.Sign() {
-		s.Assigns[p.Var()] = LitBoolTrue
+		s.assigns[p.Var()] = LitBoolTrue
 	} else {
-		s.Assigns[p.Var()] = LitBoolFalse
+		s.assigns[p.Var()] = LitBoolFalse
+	}
+	s.varData[p.Var()] = *NewVarData(from, s.decisionLevel())
+	s.trail = append(s.trail, p)
+	if s.proof != nil && from != ClaRefUndef && s.decisionLevel() == 0 {
+		s.proofUnit(p)
+	}
+	if s.propagator != nil && s.observed[p.Var()] {
+		s.propagator.NotifyAssignment(p)
 	}
-	s.VarData[p.Var()] = *NewVarData(from, s.decisionLevel())
-	s.Trail = append(s.Trail, p)
 }
 
-func (s *Solver) Propagate() ClauseReference {
+func (s *Solver) propagate() ClauseReference {
 	confl := ClaRefUndef
 
-	for s.Qhead < len(s.Trail) {
-		p := s.Trail[s.Qhead]
-		s.Qhead++
-		lastIdx := 0
-		copiedIdx := 0
-		s.Statistics.PropagationCount++
-		ws := s.Watches.Lookup(p)
-		for lastIdx < len(*ws) {
-			watcher := (*ws)[lastIdx]
-			blocker := (*ws)[lastIdx].blocker
-
-			// Try to avoid inspecting the clause.
-			if s.ValueLit(blocker) == LitBoolTrue {
-				(*ws)[copiedIdx] = (*ws)[lastIdx]
-				lastIdx++
-				copiedIdx++
-				continue
-			}
-
-			// Make sure the false literal is data[1]
-			cr := watcher.claRef
-			clause := s.ClaAllocator.GetClause(cr)
+	for s.qhead < len(s.trail) {
+		p := s.trail[s.qhead]
+		s.qhead++
+		s.statistics.PropagationCount++
+		if confl = s.propagateWatches(p, s.watches); confl != ClaRefUndef {
+			s.qhead = len(s.trail)
+		}
+	}
+	return confl
+}
 
-			falseLit := p.Flip()
-			if clause.At(0) == falseLit {
-				clause.Data[0], clause.Data[1] = clause.Data[1], falseLit
-			}
-			if v := clause.At(1); !v.Equal(falseLit) {
-				panic(fmt.Errorf("The 1th literal is not falseLit: %v %v", v, falseLit))
-			}
+//propagateWatches visits the clauses in the watches of p, whose negation is watched by them, and enqueues the implied literals
+//It returns the falsified clause or ClaRefUndef
+func (s *Solver) propagateWatches(p Lit, watches *Watches) ClauseReference {
+	confl := ClaRefUndef
+	lastIdx := 0
+	copiedIdx := 0
+	ws := watches.Lookup(p)
+	for lastIdx < len(*ws) {
+		watcher := (*ws)[lastIdx]
+		blocker := (*ws)[lastIdx].blocker
+
+		// Try to avoid inspecting the clause.
+		if s.valueLit(blocker) == LitBoolTrue {
+			(*ws)[copiedIdx] = (*ws)[lastIdx]
 			lastIdx++
+			copiedIdx++
+			continue
+		}
 
-			// If 0th watch is true, then clause is already satisfied
-			firstLiteral := clause.At(0)
-			w := NewWatcher(cr, firstLiteral)
-			if firstLiteral != blocker && s.ValueLit(firstLiteral) == LitBoolTrue {
-				(*ws)[copiedIdx] = w
-				copiedIdx++
-				continue
-			}
+		// Make sure the false literal is data[1]
+		cr := watcher.claRef
+		clause := s.claAllocator.GetClause(cr)
 
-			// Look for new watch:
-			for i := 2; i < clause.Size(); i++ {
-				//Find the candidate for watching
-				if s.ValueLit(clause.At(i)) != LitBoolFalse {
-					clause.Data[1], clause.Data[i] = clause.Data[i], falseLit
-					x := clause.At(1)
-					s.Watches.Append(x.Flip(), w)
-					goto NextClause
-				}
-			}
-			// Did not find watch -- clause is unit under assignment:
+		falseLit := p.Flip()
+		if clause.At(0) == falseLit {
+			clause.Data[0], clause.Data[1] = clause.Data[1], falseLit
+		}
+		if v := clause.At(1); !v.Equal(falseLit) {
+			panic(fmt.Errorf("The 1th literal is not falseLit: %v %v", v, falseLit))
+		}
+		lastIdx++
+
+		// If 0th watch is true, then clause is already satisfied
+		firstLiteral := clause.At(0)
+		w := NewWatcher(cr, firstLiteral)
+		if firstLiteral != blocker && s.valueLit(firstLiteral) == LitBoolTrue {
 			(*ws)[copiedIdx] = w
 			copiedIdx++
-			if s.ValueLit(firstLiteral) == LitBoolFalse {
-				confl = cr
-				s.Qhead = len(s.Trail)
-				//Copy the remaining watches:
-				for lastIdx < len(*ws) {
-					(*ws)[copiedIdx] = (*ws)[lastIdx]
-					lastIdx++
-					copiedIdx++
-				}
-			} else {
-				s.UncheckedEnqueue(firstLiteral, cr)
+			continue
+		}
+
+		// Look for new watch:
+		for i := 2; i < clause.Size(); i++ {
+			//Find the candidate for watching
+			if s.valueLit(clause.At(i)) != LitBoolFalse {
+				clause.Data[1], clause.Data[i] = clause.Data[i], falseLit
+				x := clause.At(1)
+				watches.Append(x.Flip(), w)
+				goto NextClause
 			}
-		NextClause:
 		}
-		//shrink
-		(*ws) = (*ws)[:copiedIdx]
+		// Did not find watch -- clause is unit under assignment:
+		(*ws)[copiedIdx] = w
+		copiedIdx++
+		if s.valueLit(firstLiteral) == LitBoolFalse {
+			confl = cr
+			//Copy the remaining watches:
+			for lastIdx < len(*ws) {
+				(*ws)[copiedIdx] = (*ws)[lastIdx]
+				lastIdx++
+				copiedIdx++
+			}
+		} else {
+			s.uncheckedEnqueue(firstLiteral, cr)
+		}
+	NextClause:
 	}
-
+	//shrink
+	(*ws) = (*ws)[:copiedIdx]
 	return confl
 }
 
 func (s *Solver) reduceDB() {
 	//sort
-	sort.Slice(s.LearntClauses, func(i, j int) bool {
-		x := s.LearntClauses[i]
-		y := s.LearntClauses[j]
-		clauseX := s.ClaAllocator.GetClause(x)
-		clauseY := s.ClaAllocator.GetClause(y)
+	sort.Slice(s.learnts, func(i, j int) bool {
+		x := s.learnts[i]
+		y := s.learnts[j]
+		clauseX := s.claAllocator.GetClause(x)
+		clauseY := s.claAllocator.GetClause(y)
 
 		if clauseX.Size() > 2 {
 			if clauseY.Size() == 2 || clauseX.Activity() < clauseY.Activity() {
@@ -233,50 +302,62 @@ func (s *Solver) reduceDB() {
 	})
 
 	copiedIdx := 0
-	remainActivityMaxLimit := s.ClauseActitvyIncreaseRatio / float32(len(s.LearntClauses))
-	for i := 0; i < len(s.LearntClauses); i++ {
-		claRef := s.LearntClauses[i]
-		clause := s.ClaAllocator.GetClause(claRef)
+	remainActivityMaxLimit := s.clauseActivityIncreaseRatio / float32(len(s.learnts))
+	for i := 0; i < len(s.learnts); i++ {
+		claRef := s.learnts[i]
+		clause := s.claAllocator.GetClause(claRef)
 
-		if clause.Size() > 2 && !s.locked(clause) && (i < len(s.LearntClauses)/2 || clause.Activity() < remainActivityMaxLimit) {
+		if clause.Size() > 2 && !s.locked(clause) && (i < len(s.learnts)/2 || clause.Activity() < remainActivityMaxLimit) {
 			s.removeClause(claRef)
-			s.Statistics.RemovedClauseCount++
+			s.statistics.RemovedClauseCount++
 		} else {
-			s.LearntClauses[copiedIdx] = claRef
+			s.learnts[copiedIdx] = claRef
 			copiedIdx++
 		}
 	}
-	s.LearntClauses = s.LearntClauses[:copiedIdx]
+	s.learnts = s.learnts[:copiedIdx]
 }
 
-func (s *Solver) CancelUntil(level int) {
+func (s *Solver) cancelUntil(level int) {
 	if s.decisionLevel() > level {
-		for c := len(s.Trail) - 1; c >= s.TrailLim[level]; c-- {
-			x := s.Trail[c].Var()
-			s.Assigns[x] = LitBoolUndef
+		for c := len(s.trail) - 1; c >= s.trailLim[level]; c-- {
+			x := s.trail[c].Var()
+			s.assigns[x] = LitBoolUndef
 
-			if s.Trail[c].Sign() {
-				s.Polarity[x] = LitBoolFalse
+			if s.trail[c].Sign() {
+				s.polarity[x] = LitBoolFalse
 			} else {
-				s.Polarity[x] = LitBoolTrue
+				s.polarity[x] = LitBoolTrue
 			}
-			s.InsertVarOrder(x)
+			s.insertVarOrder(x)
+		}
+		s.qhead = s.trailLim[level]
+		s.trail = s.trail[:s.qhead]
+		s.trailLim = s.trailLim[:level]
+		if s.propagator != nil {
+			s.propagator.NotifyBacktrack(level)
 		}
-		s.Qhead = s.TrailLim[level]
-		s.Trail = s.Trail[:s.Qhead]
-		s.TrailLim = s.TrailLim[:level]
 	}
 }
 
 func (s *Solver) pickBranchLit() Lit {
-	// Activity based decision
 	nextVar := VarUndef
-	for nextVar == VarUndef || s.ValueVar(nextVar) != LitBoolUndef || !s.Decision[nextVar] {
-		if s.VarOrder.Empty() {
+
+	// Random decision:
+	if s.opts.RandomVarFreq > 0 && !s.varOrder.Empty() && s.drand() < s.opts.RandomVarFreq {
+		nextVar = s.varOrder.data[s.irand(s.varOrder.Size())]
+		if s.valueVar(nextVar) == LitBoolUndef && s.decision[nextVar] {
+			s.statistics.RandomDecisionCount++
+		}
+	}
+
+	// Activity based decision
+	for nextVar == VarUndef || s.valueVar(nextVar) != LitBoolUndef || !s.decision[nextVar] {
+		if s.varOrder.Empty() {
 			nextVar = VarUndef
 			break
 		}
-		nextVar = s.VarOrder.RemoveMin()
+		nextVar = s.varOrder.RemoveMin()
 	}
 
 	if nextVar == VarUndef {
@@ -285,27 +366,71 @@ func (s *Solver) pickBranchLit() Lit {
 
 	//The default polarity is true. (!x1 = true)
 	sign := true
-	if s.Polarity[nextVar] == LitBoolTrue {
+	if s.userPolarity[nextVar] != LitBoolUndef {
+		sign = s.userPolarity[nextVar] == LitBoolFalse
+	} else if s.polarity[nextVar] == LitBoolTrue {
 		sign = false
 	}
 	return *NewLit(nextVar, sign)
 }
 
+//drand returns a random float in [0, 1) and updates the seed
+func (s *Solver) drand() float64 {
+	s.randomSeed *= 1389796
+	q := int(s.randomSeed / 2147483647)
+	s.randomSeed -= float64(q) * 2147483647
+	return s.randomSeed / 2147483647
+}
+
+//irand returns a random integer in [0, size)
+func (s *Solver) irand(size int) int {
+	return int(s.drand() * float64(size))
+}
+
 func (s *Solver) newDecisionLevel() {
-	s.TrailLim = append(s.TrailLim, len(s.Trail))
+	s.trailLim = append(s.trailLim, len(s.trail))
+	if s.propagator != nil {
+		s.propagator.NotifyNewDecisionLevel()
+	}
 }
 
 func (s *Solver) decisionLevel() int {
-	return len(s.TrailLim)
+	return len(s.trailLim)
+}
+
+//AddClause adds a clause to the problem and returns false if the solver is already unsatisfiable
+//The literals are copied, so the caller may reuse lits
+//The clause is retracted by Pop if a scope is opened by Push
+//The clauses removed by the variable elimination are added back if the clause has an eliminated variable
+func (s *Solver) AddClause(lits []Lit) bool {
+	ps := make([]Lit, len(lits), len(lits)+1)
+	copy(ps, lits)
+	if len(s.scopes) > 0 {
+		ps = append(ps, s.scopes[len(s.scopes)-1].Flip())
+	}
+	if s.clauseStore != nil && s.clauseStoreErr == nil {
+		s.clauseStoreErr = s.clauseStore.Append(ps)
+	}
+	s.restoreEliminated(ps)
+	return s.addClause(ps)
 }
 
 func (s *Solver) addClause(lits []Lit) bool {
+	return s.addClauseWithID(s.newClauseID(), lits)
+}
+
+//addClauseWithID adds the clause with the ID at decision level 0
+func (s *Solver) addClauseWithID(id uint64, lits []Lit) bool {
 	if s.decisionLevel() != 0 {
 		panic(fmt.Errorf("The decision level is not zero: %d", s.decisionLevel()))
 	}
-	if !s.OK {
+	if !s.ok {
 		return false
 	}
+	var original []Lit
+	if s.proof != nil {
+		original = append(original, lits...)
+	}
 	//The speed of solver become too slow!!
 	sort.Slice(lits, func(i, j int) bool {
 		return lits[i].X < lits[j].X
@@ -315,36 +440,43 @@ func (s *Solver) addClause(lits []Lit) bool {
 	p := Lit{X: LitUndef}
 	copiedIdx := 0
 	for i := 0; i < len(lits); i++ {
-		if s.ValueLit(lits[i]) == LitBoolTrue || lits[i].Equal(p.Flip()) {
+		if s.valueLit(lits[i]) == LitBoolTrue || lits[i].Equal(p.Flip()) {
 			return true
-		} else if s.ValueLit(lits[i]) != LitBoolFalse && lits[i].NotEqual(p) {
+		} else if s.valueLit(lits[i]) != LitBoolFalse && lits[i].NotEqual(p) {
 			lits[copiedIdx], p = lits[i], lits[i]
 			copiedIdx++
 		}
 	}
 	lits = lits[:copiedIdx]
+	if s.proof != nil && len(lits) < len(original) {
+		id = s.proofTrimmed(id, lits, original)
+	}
 	// What clause is empty means that the problem is unsatisfiable
 	if len(lits) == 0 {
-		s.OK = false
+		s.ok = false
 	} else if len(lits) == 1 {
-		s.UncheckedEnqueue(lits[0], ClaRefUndef)
+		s.uncheckedEnqueue(lits[0], ClaRefUndef)
+		s.unitID[lits[0].Var()] = id
 		//Found conflict
-		if confl := s.Propagate(); confl != ClaRefUndef {
-			s.OK = false
+		if confl := s.propagate(); confl != ClaRefUndef {
+			s.ok = false
+			if s.proof != nil {
+				s.proofConflict(confl)
+			}
 		}
-		return false
 	} else {
-		claRef, err := s.ClaAllocator.NewAllocate(lits, false)
+		claRef, err := s.claAllocator.NewAllocate(lits, false)
 		if err != nil {
 			panic(err)
 		}
-		s.Clauses = append(s.Clauses, claRef)
+		s.claAllocator.GetClause(claRef).id = id
+		s.clauses = append(s.clauses, claRef)
 		err = s.attachClause(claRef)
 		if err != nil {
 			panic(err)
 		}
 	}
-	return true
+	return s.ok
 }
 
 func (s *Solver) luby(y float64, x int) float64 {
@@ -361,77 +493,184 @@ func (s *Solver) luby(y float64, x int) float64 {
 	return math.Pow(y, float64(seq))
 }
 
+//Solve searches a satisfying assignment of the problem
+//It returns LitBoolTrue if the problem is satisfiable, LitBoolFalse if it is unsatisfiable
+//and LitBoolUndef if the search is stopped by a budget, an interrupt or the external propagator rejecting a model without a clause
 func (s *Solver) Solve() LitBool {
-	if !s.OK {
+	return s.SolveWithAssumptionsContext(context.Background(), nil)
+}
+
+//SolveContext is the same as Solve but stops the search and returns LitBoolUndef when ctx is done
+func (s *Solver) SolveContext(ctx context.Context) LitBool {
+	return s.SolveWithAssumptionsContext(ctx, nil)
+}
+
+//SolveWithAssumptions searches a satisfying assignment in which all assumptions are true
+//Learnt clauses are kept between calls, so the solver can be called repeatedly with different assumptions
+//If it returns LitBoolFalse, FailedAssumptions returns the assumptions that caused the unsatisfiability
+func (s *Solver) SolveWithAssumptions(assumptions []Lit) LitBool {
+	return s.SolveWithAssumptionsContext(context.Background(), assumptions)
+}
+
+//SolveWithAssumptionsContext is the same as SolveWithAssumptions but stops the search and returns LitBoolUndef when ctx is done
+//The solver stays usable after the search is stopped
+func (s *Solver) SolveWithAssumptionsContext(ctx context.Context, assumptions []Lit) LitBool {
+	s.model = s.model[:0]
+	s.conflict = s.conflict[:0]
+	s.modelRejected = false
+	s.settleProof()
+	if !s.ok {
 		return LitBoolFalse
 	}
+	for _, p := range assumptions {
+		if int(p.Var()) >= s.NumVars() {
+			panic(fmt.Errorf("The assumption is not a variable of the solver: %d", p.Var()))
+		}
+	}
+	s.restoreEliminated(assumptions)
+	s.assumptions = append(append(s.assumptions[:0], s.scopes...), assumptions...)
+	if ctx.Err() != nil {
+		return LitBoolUndef
+	}
 
-	s.MaxNumLearnt = float64(s.NumClauses()) * 0.3
+	s.solving = true
+	defer func() { s.solving = false }()
 	status := LitBoolUndef
+	if !s.preprocess() {
+		status = LitBoolFalse
+	}
+	s.maxNumLearnt = float64(s.NumClauses()) * s.opts.LearntSizeFactor
 	currentRestartCount := 0
 
-	if s.Verbosity {
+	stopWatching := s.watchContext(ctx)
+	defer stopWatching()
+	done := make(chan struct{})
+	defer close(done)
+
+	if s.opts.Verbose != nil {
 		go func() {
-			fmt.Printf("c ============================[ Search Statistics ]=============================\n")
-			fmt.Printf("c | Restarts | Conflicts  | ReduceDB   | Current Learnt  | Binary Learnt | Unit Learnt |\n")
+			fmt.Fprintf(s.opts.Verbose, "c ============================[ Search Statistics ]=============================\n")
+			fmt.Fprintf(s.opts.Verbose, "c | Restarts | Conflicts  | ReduceDB   | Current Learnt  | Binary Learnt | Unit Learnt |\n")
 			ticker := time.NewTicker(3 * time.Second)
 			defer ticker.Stop()
 			for {
 				select {
 				case <-ticker.C:
-					restartCount := s.Statistics.RestartCount
-					conflictCount := s.Statistics.ConflictCount
-					currentNumLearnts := len(s.LearntClauses)
-					numUnitLearnts := s.Statistics.NumUnitLearnts
-					numBinaryLearnts := s.Statistics.NumBinaryLearnts
-					reduceDBCount := s.Statistics.ReduceDBCount
-					fmt.Printf("c | %8d | %10d | %10d |      %10d |     %9d | %5d / %d |\n", restartCount, conflictCount, reduceDBCount, currentNumLearnts, numBinaryLearnts, numUnitLearnts, s.NumVars())
+					restartCount := s.statistics.RestartCount
+					conflictCount := s.statistics.ConflictCount
+					currentNumLearnts := len(s.learnts)
+					numUnitLearnts := s.statistics.NumUnitLearnts
+					numBinaryLearnts := s.statistics.NumBinaryLearnts
+					reduceDBCount := s.statistics.ReduceDBCount
+					fmt.Fprintf(s.opts.Verbose, "c | %8d | %10d | %10d |      %10d |     %9d | %5d / %d |\n", restartCount, conflictCount, reduceDBCount, currentNumLearnts, numBinaryLearnts, numUnitLearnts, s.NumVars())
+				case <-done:
+					return
 				}
 			}
 		}()
 	}
 
-	for true {
-		restartBase := s.luby(s.RestartIncreaseRatio, currentRestartCount)
-		maxConflictCount := int(restartBase) * s.RestartFirst
+	for status == LitBoolUndef {
+		var restartBase float64
+		if s.opts.RestartPolicy == RestartLuby {
+			restartBase = s.luby(s.opts.RestartIncreaseRatio, currentRestartCount)
+		} else {
+			restartBase = math.Pow(s.opts.RestartIncreaseRatio, float64(currentRestartCount))
+		}
+		maxConflictCount := int(restartBase * float64(s.opts.RestartFirst))
 
 		status = s.search(maxConflictCount)
-		if status != LitBoolUndef {
+		if status != LitBoolUndef || s.modelRejected || !s.withinBudget() {
 			break
 		}
-		s.Statistics.RestartCount++
+		if s.opts.DebugCheckInterval > 0 {
+			s.assertInvariants()
+		}
+		s.statistics.RestartCount++
 		currentRestartCount++
 	}
 	if status == LitBoolTrue {
 		for i := 0; i < s.NumVars(); i++ {
-			s.Model = append(s.Model, s.ValueVar(Var(i)))
+			s.model = append(s.model, s.valueVar(Var(i)))
 		}
-	} else if status == LitBoolFalse {
-		s.OK = false
+		s.extendModel()
+	} else if status == LitBoolFalse && len(s.conflict) == 0 {
+		s.ok = false
+	}
+	s.cancelUntil(0)
+	if s.proof != nil {
+		s.proof.flush()
 	}
-	s.CancelUntil(0)
 	return status
 }
 
+//FailedAssumptions returns the subset of the assumptions of the last call to SolveWithAssumptions
+//which is sufficient for the unsatisfiability
+//It returns an empty list if the problem is unsatisfiable without any assumptions
+//The activation literals of the scopes are not included
+func (s *Solver) FailedAssumptions() []Lit {
+	failed := make([]Lit, 0, len(s.conflict))
+	for _, p := range s.conflict {
+		if !s.activation[p.Var()] {
+			failed = append(failed, p.Flip())
+		}
+	}
+	return failed
+}
+
+//Failed returns a boolean indicating whether the assumption p is in the final conflict
+func (s *Solver) Failed(p Lit) bool {
+	q := p.Flip()
+	for _, c := range s.conflict {
+		if c.Equal(q) {
+			return true
+		}
+	}
+	return false
+}
+
+//Model returns the satisfying assignment of the last call to Solve
+//The i-th element is the value of the i-th variable
+func (s *Solver) Model() []LitBool {
+	model := make([]LitBool, len(s.model))
+	copy(model, s.model)
+	return model
+}
+
+//Value returns the value of the variable in the model of the last call to Solve
+func (s *Solver) Value(v Var) LitBool {
+	if int(v) >= len(s.model) {
+		return LitBoolUndef
+	}
+	return s.model[v]
+}
+
+//SetPolarity fixes the value the variable is assigned first when it is picked by the decision heuristic
+//LitBoolUndef restores the default polarity, which is the last value of the variable
+func (s *Solver) SetPolarity(x Var, value LitBool) {
+	s.userPolarity[int(x)] = value
+}
+
+//SetDecisionVar declares whether the variable is eligible for selection in the decision heuristic
 func (s *Solver) SetDecisionVar(x Var, eligible bool) {
-	s.Decision[int(x)] = eligible
-	s.InsertVarOrder(x)
+	s.decision[int(x)] = eligible
+	s.insertVarOrder(x)
 }
 
 func (s *Solver) analyze(confl ClauseReference) (learntClause []Lit, backTrackLevel int) {
 
 	p := Lit{X: LitUndef}
 	pathConflict := 0
-	idx := len(s.Trail) - 1
+	idx := len(s.trail) - 1
 
 	learntClause = append(learntClause, p) // (leave room for the asserting literal)
 	for {
 
 		if confl == ClaRefUndef {
-			pp.Println(s.VarData[p.Var()], p.Var(), s.decisionLevel(), s.ValueLit(p), pathConflict)
+			pp.Println(s.varData[p.Var()], p.Var(), s.decisionLevel(), s.valueLit(p), pathConflict)
 			panic("The conflict doesn't point any regisions")
 		}
-		conflCla := s.ClaAllocator.GetClause(confl)
+		conflCla := s.claAllocator.GetClause(confl)
 
 		if conflCla.Learnt() {
 			s.clauseBumpActivity(conflCla)
@@ -444,13 +683,13 @@ func (s *Solver) analyze(confl ClauseReference) (learntClause []Lit, backTrackLe
 		}
 		for i := startIndex; i < conflCla.Size(); i++ {
 			q := conflCla.At(i)
-			if !s.Seen[q.Var()] && s.Level(q.Var()) > 0 {
+			if !s.seen[q.Var()] && s.level(q.Var()) > 0 {
 				s.varBumpActitivy(q.Var())
-				s.Seen[q.Var()] = true
-				if s.Level(q.Var()) > s.decisionLevel() {
+				s.seen[q.Var()] = true
+				if s.level(q.Var()) > s.decisionLevel() {
 					panic("The decision level of var is greater than or equal to 1")
 				}
-				if s.Level(q.Var()) == s.decisionLevel() {
+				if s.level(q.Var()) == s.decisionLevel() {
 					pathConflict++
 				} else {
 					learntClause = append(learntClause, q)
@@ -460,13 +699,13 @@ func (s *Solver) analyze(confl ClauseReference) (learntClause []Lit, backTrackLe
 		// Select next clause to look at:
 		update := true
 		for update {
-			p = s.Trail[idx]
-			update = !s.Seen[p.Var()]
+			p = s.trail[idx]
+			update = !s.seen[p.Var()]
 			idx--
 		}
 
-		confl = s.Reason(p.Var())
-		s.Seen[p.Var()] = false
+		confl = s.reason(p.Var())
+		s.seen[p.Var()] = false
 		pathConflict--
 		if pathConflict <= 0 {
 			break
@@ -481,15 +720,15 @@ func (s *Solver) analyze(confl ClauseReference) (learntClause []Lit, backTrackLe
 	copiedIdx := 1
 	for i := 1; i < len(learntClause); i++ {
 		x := learntClause[i].Var()
-		if s.Reason(x) == ClaRefUndef {
+		if s.reason(x) == ClaRefUndef {
 			learntClause[copiedIdx] = learntClause[i]
 			copiedIdx++
 		} else {
-			c := s.ClaAllocator.GetClause(s.Reason(x))
+			c := s.claAllocator.GetClause(s.reason(x))
 
 			for k := 1; k < c.Size(); k++ {
 				v := c.At(k)
-				if !s.Seen[v.Var()] && s.Level(v.Var()) > 0 {
+				if !s.seen[v.Var()] && s.level(v.Var()) > 0 {
 					learntClause[copiedIdx] = learntClause[i]
 					copiedIdx++
 					break
@@ -505,92 +744,208 @@ func (s *Solver) analyze(confl ClauseReference) (learntClause []Lit, backTrackLe
 		maxIdx := 1
 		// Find the first literal assigned at the next-highest level:
 		for i := 2; i < len(learntClause); i++ {
-			if s.Level(learntClause[i].Var()) > s.Level(learntClause[maxIdx].Var()) {
+			if s.level(learntClause[i].Var()) > s.level(learntClause[maxIdx].Var()) {
 				maxIdx = i
 			}
 		}
 
-		backTrackLevel = s.Level(learntClause[maxIdx].Var())
+		backTrackLevel = s.level(learntClause[maxIdx].Var())
 		// Swap-in this literal at index 1:
 		learntClause[maxIdx], learntClause[1] = learntClause[1], learntClause[maxIdx]
 	}
 
 	for _, lit := range analyzeToClear {
-		s.Seen[lit.Var()] = false
+		s.seen[lit.Var()] = false
 	}
 
 	return learntClause, backTrackLevel
 }
 
+//analyzeFinal specifies the set of assumptions that led to the assignment of p
+//and stores the result in conflict as a clause expressed in the negation of assumptions
+func (s *Solver) analyzeFinal(p Lit) {
+	s.conflict = append(s.conflict[:0], p)
+
+	if s.decisionLevel() == 0 {
+		return
+	}
+
+	s.seen[p.Var()] = true
+
+	for i := len(s.trail) - 1; i >= s.trailLim[0]; i-- {
+		x := s.trail[i].Var()
+		if !s.seen[x] {
+			continue
+		}
+		if s.reason(x) == ClaRefUndef {
+			if s.level(x) <= 0 {
+				panic(fmt.Errorf("The level of the assumption is not greater than 0: %d", s.level(x)))
+			}
+			s.conflict = append(s.conflict, s.trail[i].Flip())
+		} else {
+			c := s.claAllocator.GetClause(s.reason(x))
+			for k := 1; k < c.Size(); k++ {
+				q := c.At(k)
+				if s.level(q.Var()) > 0 {
+					s.seen[q.Var()] = true
+				}
+			}
+		}
+		s.seen[x] = false
+	}
+	s.seen[p.Var()] = false
+}
+
 func (s *Solver) simplify() bool {
 	if s.decisionLevel() != 0 {
 		panic(fmt.Errorf("The decision level is not zero: %d", s.decisionLevel()))
 	}
 
-	if !s.OK || s.Propagate() != ClaRefUndef {
-		s.OK = false
+	if !s.ok {
 		return false
 	}
+	if confl := s.propagate(); confl != ClaRefUndef {
+		s.ok = false
+		if s.proof != nil {
+			s.proofConflict(confl)
+		}
+		return false
+	}
+
+	s.removeSatisfied(&s.learnts)
+	s.removeSatisfied(&s.clauses)
+	return true
+}
 
-	s.removeSatisfied(&s.LearntClauses)
-	s.removeSatisfied(&s.Clauses)
+//computeLBD returns the number of distinct decision levels of the literals
+func (s *Solver) computeLBD(lits []Lit) int {
+	s.lbdStamp++
+	lbd := 0
+	for _, q := range lits {
+		l := s.level(q.Var())
+		for len(s.lbdLevels) <= l {
+			s.lbdLevels = append(s.lbdLevels, 0)
+		}
+		if s.lbdLevels[l] != s.lbdStamp {
+			s.lbdLevels[l] = s.lbdStamp
+			lbd++
+		}
+	}
+	return lbd
+}
+
+//importExternalClauses adds the clauses returned by the import function at decision level 0
+func (s *Solver) importExternalClauses() bool {
+	if s.decisionLevel() != 0 {
+		panic(fmt.Errorf("The decision level is not zero: %d", s.decisionLevel()))
+	}
+	for _, lits := range s.importClauses() {
+		eliminated := false
+		for _, q := range lits {
+			if int(q.Var()) >= s.NumVars() {
+				panic(fmt.Errorf("The imported clause contains an unknown variable: %d", q.Var()))
+			}
+			eliminated = eliminated || s.eliminated[q.Var()] || s.witness[q.Var()]
+		}
+		if eliminated {
+			//The extension of the model may falsify a clause of an eliminated or witness variable
+			continue
+		}
+		s.statistics.ImportedClauseCount++
+		s.addExternalClause(lits, true)
+		if !s.ok {
+			return false
+		}
+	}
 	return true
 }
 
 func (s *Solver) search(maxConflictCount int) LitBool {
-	if !s.OK {
-		panic("s.OK is false")
+	if !s.ok {
+		panic("s.ok is false")
 	}
 
 	conflictCount := 0
+	if s.importClauses != nil && !s.importExternalClauses() {
+		return LitBoolFalse
+	}
+	if s.opts.Probing && s.statistics.ConflictCount >= s.nextProbe && !s.probe() {
+		return LitBoolFalse
+	}
 
 	for {
-		confl := s.Propagate()
+		confl := s.propagate()
+		if confl == ClaRefUndef && s.propagator != nil {
+			confl = s.externalPropagate()
+			if !s.ok {
+				return LitBoolFalse
+			}
+		}
 		if confl != ClaRefUndef {
 			//Conflict
-			s.Statistics.ConflictCount++
+			s.statistics.ConflictCount++
 			conflictCount++
 
 			//If the decision level is 0, the problem is unsatisfiable.
 			if s.decisionLevel() == 0 {
+				if s.proof != nil {
+					s.proofConflict(confl)
+				}
 				return LitBoolFalse
 			}
 
 			learntClause, backTrackLevel := s.analyze(confl)
-			s.CancelUntil(backTrackLevel)
+			if s.learn != nil && len(learntClause) <= s.learnMaxLength {
+				s.learn(append([]Lit(nil), learntClause...), s.computeLBD(learntClause))
+			}
+			id := s.newClauseID()
+			if s.proof != nil {
+				var hints []uint64
+				if s.proof.format == ProofLRAT {
+					hints = s.lratHints(confl, learntClause)
+				}
+				s.proof.add(id, learntClause, hints)
+			}
+			s.cancelUntil(backTrackLevel)
 
 			if len(learntClause) == 1 {
-				s.Statistics.NumUnitLearnts++
-				s.UncheckedEnqueue(learntClause[0], ClaRefUndef)
+				s.statistics.NumUnitLearnts++
+				s.uncheckedEnqueue(learntClause[0], ClaRefUndef)
+				s.unitID[learntClause[0].Var()] = id
 			} else {
 				if len(learntClause) == 2 {
-					s.Statistics.NumBinaryLearnts++
+					s.statistics.NumBinaryLearnts++
 				}
-				claRef, err := s.ClaAllocator.NewAllocate(learntClause, true)
+				claRef, err := s.claAllocator.NewAllocate(learntClause, true)
 				if err != nil {
 					panic(err)
 				}
-				s.LearntClauses = append(s.LearntClauses, claRef)
+				s.claAllocator.GetClause(claRef).id = id
+				s.learnts = append(s.learnts, claRef)
 				err = s.attachClause(claRef)
 				if err != nil {
 					panic(err)
 				}
-				c := s.ClaAllocator.GetClause(claRef)
+				c := s.claAllocator.GetClause(claRef)
 				s.clauseBumpActivity(c)
-				s.UncheckedEnqueue(learntClause[0], claRef)
+				s.uncheckedEnqueue(learntClause[0], claRef)
 			}
 
 			s.varDecayActivity()
 			s.clauseDecayActivity()
-			if conflictCount >= int(s.LearntSizeAdjustConflict) {
-				s.LearntSizeAdjustConflict *= 1.5
-				s.MaxNumLearnt *= 1.1
+			if conflictCount >= int(s.learntSizeAdjustConflict) {
+				s.learntSizeAdjustConflict *= s.opts.LearntSizeAdjustIncrease
+				s.maxNumLearnt *= s.opts.LearntSizeIncreaseRatio
 			}
 		} else {
 			//NO CONFLICT
-			if maxConflictCount >= 0 && conflictCount > maxConflictCount {
+			if s.opts.DebugCheckInterval > 0 && s.statistics.ConflictCount >= s.nextInvariantCheck {
+				s.nextInvariantCheck = s.statistics.ConflictCount + uint64(s.opts.DebugCheckInterval)
+				s.assertInvariants()
+			}
+			if maxConflictCount >= 0 && conflictCount > maxConflictCount || !s.withinBudget() {
 				//Restart
-				s.CancelUntil(0)
+				s.cancelUntil(0)
 				return LitBoolUndef
 			}
 
@@ -598,27 +953,54 @@ func (s *Solver) search(maxConflictCount int) LitBool {
 			if s.decisionLevel() == 0 && !s.simplify() {
 				return LitBoolFalse
 			}
+			//Remove the subsumed clauses periodically
+			if s.decisionLevel() == 0 && s.opts.Subsumption && s.statistics.ConflictCount >= s.nextSubsumption && !s.subsume() {
+				return LitBoolFalse
+			}
 
-			if len(s.LearntClauses)-s.NumAssigns() >= int(s.MaxNumLearnt) {
+			if len(s.learnts)-s.NumAssigns() >= int(s.maxNumLearnt) {
 				//Reduce the set of learnt clauses:
-				s.Statistics.ReduceDBCount++
+				s.statistics.ReduceDBCount++
 				//Increase the threshold for the learnt clause
 				//avoid to call reduceDB many times
-				s.MaxNumLearnt *= 1.1
+				s.maxNumLearnt *= s.opts.LearntSizeIncreaseRatio
 				s.reduceDB()
 			}
 			nextLit := Lit{X: LitUndef}
+			for s.decisionLevel() < len(s.assumptions) {
+				//Perform user provided assumption:
+				p := s.assumptions[s.decisionLevel()]
+				if s.valueLit(p) == LitBoolTrue {
+					//Dummy decision level:
+					s.newDecisionLevel()
+				} else if s.valueLit(p) == LitBoolFalse {
+					s.analyzeFinal(p.Flip())
+					return LitBoolFalse
+				} else {
+					nextLit = p
+					break
+				}
+			}
 
 			if nextLit.X == LitUndef {
-				s.Statistics.DecisionCount++
+				s.statistics.DecisionCount++
 				nextLit = s.pickBranchLit()
 				if nextLit.X == LitUndef {
+					if s.propagator != nil && !s.checkExternalModel() {
+						if !s.propagator.HasExternalClause() {
+							//The propagator rejects the model without a clause, so the same model would be found again
+							s.modelRejected = true
+							s.cancelUntil(0)
+							return LitBoolUndef
+						}
+						continue
+					}
 					// Model found:
 					return LitBoolTrue
 				}
 			}
 			s.newDecisionLevel()
-			s.UncheckedEnqueue(nextLit, ClaRefUndef)
+			s.uncheckedEnqueue(nextLit, ClaRefUndef)
 		}
 	}
 }
@@ -1,9 +1,11 @@
-package main
+package gatosat
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io/ioutil"
+	"math/rand"
 	"os"
 	"path/filepath"
 	"strings"
@@ -31,7 +33,7 @@ func TestSolve(t *testing.T) {
 			buf := bufio.NewScanner(f)
 			fmt.Println("The solver is solving a sat problem... ", fileName)
 			solver := NewSolver()
-			err = parseDimacs(buf, solver)
+			err = ParseDimacs(buf, solver)
 			if err != nil {
 				fmt.Println(err, fileName)
 				continue
@@ -63,7 +65,7 @@ func TestSolve(t *testing.T) {
 			buf := bufio.NewScanner(f)
 			fmt.Println("The solver is solving a unsat problem... ", fileName)
 			solver := NewSolver()
-			err = parseDimacs(buf, solver)
+			err = ParseDimacs(buf, solver)
 			if err != nil {
 				fmt.Println(err, fileName)
 				continue
@@ -77,3 +79,260 @@ func TestSolve(t *testing.T) {
 	}
 
 }
+
+func TestModelValue(t *testing.T) {
+	s := NewSolver()
+	x := s.NewVar()
+	y := s.NewVar()
+	lits := []Lit{*NewLit(x, false), *NewLit(y, false)}
+	s.AddClause(lits)
+	s.AddClause([]Lit{*NewLit(x, true)})
+	if lits[0] != *NewLit(x, false) || lits[1] != *NewLit(y, false) {
+		t.Fatalf("AddClause modified the literals: %v", lits)
+	}
+	if status := s.Solve(); status != LitBoolTrue {
+		t.Fatalf("The solver returns a wrong value for a sat problem: %v", status)
+	}
+	if s.Value(x) != LitBoolFalse || s.Value(y) != LitBoolTrue {
+		t.Fatalf("The model is wrong: %v", s.Model())
+	}
+}
+
+func TestSolveWithAssumptions(t *testing.T) {
+	s := NewSolver()
+	x := s.NewVar()
+	y := s.NewVar()
+	z := s.NewVar()
+	s.AddClause([]Lit{*NewLit(x, false), *NewLit(y, false)})
+	s.AddClause([]Lit{*NewLit(x, true), *NewLit(z, false)})
+
+	assumptions := []Lit{*NewLit(y, true), *NewLit(z, true)}
+	if status := s.SolveWithAssumptions(assumptions); status != LitBoolFalse {
+		t.Fatalf("The solver returns a wrong value under assumptions: %v", status)
+	}
+	failed := s.FailedAssumptions()
+	if len(failed) != 2 || !s.Failed(assumptions[0]) || !s.Failed(assumptions[1]) {
+		t.Fatalf("The failed assumptions are wrong: %v", failed)
+	}
+
+	if status := s.SolveWithAssumptions(assumptions[:1]); status != LitBoolTrue {
+		t.Fatalf("The solver returns a wrong value under assumptions: %v", status)
+	}
+	if s.Value(x) != LitBoolTrue || s.Value(y) != LitBoolFalse || s.Value(z) != LitBoolTrue {
+		t.Fatalf("The model is wrong: %v", s.Model())
+	}
+	if status := s.Solve(); status != LitBoolTrue || len(s.Model()) != s.NumVars() {
+		t.Fatalf("The solver returns a wrong value without assumptions: %v %v", status, s.Model())
+	}
+}
+
+func TestSolveWithAssumptionsRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for iter := 0; iter < 200; iter++ {
+		numVars := 3 + rnd.Intn(6)
+		clauses := randomClauses(rnd, numVars, 2+rnd.Intn(4*numVars))
+		s := NewSolver()
+		for i := 0; i < numVars; i++ {
+			s.NewVar()
+		}
+		for _, c := range clauses {
+			s.AddClause(c)
+		}
+		for k := 0; k < 5; k++ {
+			var assumptions []Lit
+			for v := 0; v < numVars; v++ {
+				if rnd.Intn(4) == 0 {
+					assumptions = append(assumptions, *NewLit(Var(v), rnd.Intn(2) == 0))
+				}
+			}
+			expected := bruteForce(numVars, clauses, assumptions)
+			status := s.SolveWithAssumptions(assumptions)
+			if expected != status {
+				t.Fatalf("The solver returns a wrong value: expected %v got %v clauses %v assumptions %v", expected, status, clauses, assumptions)
+			}
+			if status == LitBoolTrue {
+				checkModel(t, s.Model(), clauses, assumptions)
+			} else if status == LitBoolFalse {
+				failed := s.FailedAssumptions()
+				if bruteForce(numVars, clauses, failed) != LitBoolFalse {
+					t.Fatalf("The failed assumptions are satisfiable: %v", failed)
+				}
+			}
+		}
+	}
+}
+
+func randomClauses(rnd *rand.Rand, numVars, numClauses int) [][]Lit {
+	clauses := make([][]Lit, numClauses)
+	for i := range clauses {
+		size := 1 + rnd.Intn(3)
+		for j := 0; j < size; j++ {
+			clauses[i] = append(clauses[i], *NewLit(Var(rnd.Intn(numVars)), rnd.Intn(2) == 0))
+		}
+	}
+	return clauses
+}
+
+func bruteForce(numVars int, clauses [][]Lit, assumptions []Lit) LitBool {
+	for mask := 0; mask < 1<<uint(numVars); mask++ {
+		model := make([]LitBool, numVars)
+		for v := 0; v < numVars; v++ {
+			if mask&(1<<uint(v)) != 0 {
+				model[v] = LitBoolTrue
+			} else {
+				model[v] = LitBoolFalse
+			}
+		}
+		if satisfiedBy(model, clauses, assumptions) {
+			return LitBoolTrue
+		}
+	}
+	return LitBoolFalse
+}
+
+func satisfiedBy(model []LitBool, clauses [][]Lit, assumptions []Lit) bool {
+	value := func(p Lit) bool {
+		return (model[p.Var()] == LitBoolTrue) != p.Sign()
+	}
+	for _, p := range assumptions {
+		if !value(p) {
+			return false
+		}
+	}
+	for _, c := range clauses {
+		sat := false
+		for _, p := range c {
+			if value(p) {
+				sat = true
+				break
+			}
+		}
+		if !sat {
+			return false
+		}
+	}
+	return true
+}
+
+func checkModel(t *testing.T, model []LitBool, clauses [][]Lit, assumptions []Lit) {
+	t.Helper()
+	if !satisfiedBy(model, clauses, assumptions) {
+		t.Fatalf("The model is wrong: %v clauses %v assumptions %v", model, clauses, assumptions)
+	}
+}
+
+func pigeonHole(s *Solver, pigeons, holes int) {
+	x := make([][]Var, pigeons)
+	for i := range x {
+		x[i] = make([]Var, holes)
+		for j := range x[i] {
+			x[i][j] = s.NewVar()
+		}
+	}
+	for i := 0; i < pigeons; i++ {
+		var c []Lit
+		for j := 0; j < holes; j++ {
+			c = append(c, *NewLit(x[i][j], false))
+		}
+		s.AddClause(c)
+	}
+	for j := 0; j < holes; j++ {
+		for i := 0; i < pigeons; i++ {
+			for k := i + 1; k < pigeons; k++ {
+				s.AddClause([]Lit{*NewLit(x[i][j], true), *NewLit(x[k][j], true)})
+			}
+		}
+	}
+}
+
+func TestSolveBudget(t *testing.T) {
+	s := NewSolver()
+	pigeonHole(s, 6, 5)
+	s.SetConflictBudget(5)
+	if status := s.Solve(); status != LitBoolUndef {
+		t.Fatalf("The solver doesn't stop with the conflict budget: %v", status)
+	}
+	s.BudgetOff()
+	s.SetPropagationBudget(10)
+	if status := s.Solve(); status != LitBoolUndef {
+		t.Fatalf("The solver doesn't stop with the propagation budget: %v", status)
+	}
+	s.BudgetOff()
+	if status := s.Solve(); status != LitBoolFalse {
+		t.Fatalf("The solver returns a wrong value after the budget is off: %v", status)
+	}
+}
+
+func TestSolveContext(t *testing.T) {
+	s := NewSolver()
+	pigeonHole(s, 6, 5)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if status := s.SolveContext(ctx); status != LitBoolUndef {
+		t.Fatalf("The solver doesn't stop with the cancelled context: %v", status)
+	}
+	if status := s.SolveContext(context.Background()); status != LitBoolFalse {
+		t.Fatalf("The solver returns a wrong value after the cancellation: %v", status)
+	}
+}
+
+func TestSolveCallbacks(t *testing.T) {
+	s := NewSolver()
+	pigeonHole(s, 7, 6)
+	var learnts [][]Lit
+	s.SetLearn(8, func(lits []Lit, lbd int) {
+		if len(lits) > 8 || lbd < 1 || lbd > len(lits) {
+			t.Fatalf("The learnt clause has a wrong size or LBD: %d %d", len(lits), lbd)
+		}
+		//The clause is a copy, so it is retained as it is
+		learnts = append(learnts, lits)
+	})
+	polls := 0
+	s.SetTerminate(func() bool {
+		polls++
+		return false
+	})
+	if status := s.Solve(); status != LitBoolFalse {
+		t.Fatalf("The solver returns a wrong value: %v", status)
+	}
+	if len(learnts) == 0 || polls == 0 {
+		t.Fatalf("The callbacks are not called: %d learnts %d polls", len(learnts), polls)
+	}
+
+	//Share the learnt clauses with another solver
+	other := NewSolver()
+	pigeonHole(other, 7, 6)
+	imports := 0
+	other.SetImport(func() [][]Lit {
+		imports++
+		shared := learnts
+		learnts = nil
+		return shared
+	})
+	if status := other.Solve(); status != LitBoolFalse {
+		t.Fatalf("The solver returns a wrong value with the imported clauses: %v", status)
+	}
+	if imports == 0 || other.Statistics().ImportedClauseCount == 0 {
+		t.Fatalf("The clauses are not imported: %d calls", imports)
+	}
+
+	s = NewSolver()
+	pigeonHole(s, 7, 6)
+	s.SetTerminate(func() bool { return true })
+	if status := s.Solve(); status != LitBoolUndef {
+		t.Fatalf("The solver doesn't stop with the terminate function: %v", status)
+	}
+}
+
+func TestSetPolarity(t *testing.T) {
+	s := NewSolver()
+	x, y := s.NewVar(), s.NewVar()
+	s.AddClause([]Lit{*NewLit(x, false), *NewLit(y, false), *NewLit(x, true)})
+	for _, value := range []LitBool{LitBoolTrue, LitBoolFalse} {
+		s.SetPolarity(x, value)
+		s.SetPolarity(y, value)
+		if status := s.Solve(); status != LitBoolTrue || s.Value(x) != value || s.Value(y) != value {
+			t.Fatalf("The polarity is ignored: %v %v %v", status, s.Value(x), s.Value(y))
+		}
+	}
+}
@@ -1,33 +1,67 @@
-package main
+package gatosat
 
+// Statistics is the structure for counters of the search
 type Statistics struct {
-	RestartCount       uint64
-	DecisionCount      uint64
-	PropagationCount   uint64
-	ConflictCount      uint64
-	NumLearnts         uint64
-	NumUnitLearnts     uint64
-	NumBinaryLearnts   uint64
-	NumClauses         uint64
-	ReduceDBCount      uint64
-	RemovedClauseCount uint64
+	RestartCount            uint64
+	DecisionCount           uint64
+	RandomDecisionCount     uint64
+	PropagationCount        uint64
+	ConflictCount           uint64
+	NumLearnts              uint64
+	NumUnitLearnts          uint64
+	NumBinaryLearnts        uint64
+	NumClauses              uint64
+	ReduceDBCount           uint64
+	RemovedClauseCount      uint64
+	ImportedClauseCount     uint64
+	EliminatedVarCount      uint64
+	SubsumedClauseCount     uint64
+	StrengthenedClauseCount uint64
+	ProbeCount              uint64
+	ProbedLiteralCount      uint64
+	FailedLiteralCount      uint64
+	HyperBinaryCount        uint64
+	ProbePropagationCount   uint64
+	SubstitutedVarCount     uint64
+	BlockedClauseCount      uint64
+	CoveredClauseCount      uint64
 }
 
+// NewStatistics returns a pointer of Statistics whose counters are zero
 func NewStatistics() *Statistics {
 	return &Statistics{
-		RestartCount:       0,
-		DecisionCount:      0,
-		PropagationCount:   0,
-		ConflictCount:      0,
-		NumLearnts:         0,
-		NumUnitLearnts:     0,
-		NumBinaryLearnts:   0,
-		NumClauses:         0,
-		ReduceDBCount:      0,
-		RemovedClauseCount: 0,
+		RestartCount:            0,
+		DecisionCount:           0,
+		RandomDecisionCount:     0,
+		PropagationCount:        0,
+		ConflictCount:           0,
+		NumLearnts:              0,
+		NumUnitLearnts:          0,
+		NumBinaryLearnts:        0,
+		NumClauses:              0,
+		ReduceDBCount:           0,
+		RemovedClauseCount:      0,
+		ImportedClauseCount:     0,
+		EliminatedVarCount:      0,
+		SubsumedClauseCount:     0,
+		StrengthenedClauseCount: 0,
+		ProbeCount:              0,
+		ProbedLiteralCount:      0,
+		FailedLiteralCount:      0,
+		HyperBinaryCount:        0,
+		ProbePropagationCount:   0,
+		SubstitutedVarCount:     0,
+		BlockedClauseCount:      0,
+		CoveredClauseCount:      0,
 	}
 }
 
+// Statistics returns a copy of the statistics of the search
+func (s *Solver) Statistics() Statistics {
+	return *s.statistics
+}
+
+// NumClauses returns the number of the problem clauses
 func (s *Solver) NumClauses() uint64 {
-	return s.Statistics.NumClauses
+	return s.statistics.NumClauses
 }
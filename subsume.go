@@ -0,0 +1,199 @@
+package gatosat
+
+import (
+	"sort"
+)
+
+const (
+	//subsumeInterval is the number of the conflicts between two subsumption passes in the search
+	subsumeInterval = 5000
+	//subsumeClauseLimit is the maximum size of a clause tried as a subsuming clause
+	subsumeClauseLimit = 100
+	//subsumeOccurrenceLimit is the maximum number of the clauses of a literal tried by a subsuming clause
+	subsumeOccurrenceLimit = 1000
+)
+
+//subsume removes the clauses subsumed by other clauses and strengthens the clauses by self-subsuming resolution at level 0
+//Both the problem clauses and the learnt clauses are used. A learnt clause which subsumes a problem clause becomes a problem clause.
+//Every clause is tried as a subsuming clause from the shortest, and a strengthened clause is tried again,
+//so a clause is subsumed by any clause in the end
+//It returns false if the problem is unsatisfiable
+func (s *Solver) subsume() bool {
+	s.nextSubsumption = s.statistics.ConflictCount + subsumeInterval
+	if !s.simplify() {
+		return false
+	}
+	refs := append(append([]ClauseReference(nil), s.clauses...), s.learnts...)
+	occ := make([][]ClauseReference, 2*s.NumVars())
+	signatures := map[ClauseReference]uint64{}
+	for _, cr := range refs {
+		s.addOccurrences(occ, cr)
+		signatures[cr] = s.signature(s.claAllocator.GetClause(cr))
+	}
+	queue := append([]ClauseReference(nil), refs...)
+	sort.SliceStable(queue, func(i, j int) bool {
+		return s.claAllocator.Clauses[queue[i]].Size() < s.claAllocator.Clauses[queue[j]].Size()
+	})
+
+	marks := make([]bool, 2*s.NumVars())
+	for i := 0; i < len(queue) && s.ok; i++ {
+		cr := queue[i]
+		if s.claAllocator.Clauses[cr].IsRemoved() {
+			continue
+		}
+		c := s.claAllocator.GetClause(cr)
+		if c.Size() > subsumeClauseLimit || s.satisfied(c) {
+			continue
+		}
+		//The clauses subsumed or strengthened by c contain the literal of c with the fewest occurrences or its negation
+		best := c.At(0)
+		for k := 1; k < c.Size(); k++ {
+			if q := c.At(k); len(occ[q.X])+len(occ[q.Flip().X]) < len(occ[best.X])+len(occ[best.Flip().X]) {
+				best = q
+			}
+		}
+		if len(occ[best.X])+len(occ[best.Flip().X]) > subsumeOccurrenceLimit {
+			continue
+		}
+		candidates := append(append([]ClauseReference(nil), occ[best.X]...), occ[best.Flip().X]...)
+		for _, dr := range candidates {
+			if dr == cr || s.claAllocator.Clauses[dr].IsRemoved() || c.IsRemoved() {
+				continue
+			}
+			d := s.claAllocator.GetClause(dr)
+			if d.Size() < c.Size() || signatures[cr]&^signatures[dr] != 0 || s.satisfied(d) {
+				continue
+			}
+			subsumed, flipped := subsumes(c, d, marks)
+			if !subsumed {
+				continue
+			}
+			if flipped.X == LitUndef {
+				if c.Learnt() && !d.Learnt() {
+					c.header.Learnt = false
+					s.statistics.NumLearnts--
+					s.statistics.NumClauses++
+				}
+				s.removeClause(dr)
+				s.statistics.SubsumedClauseCount++
+				continue
+			}
+			if !s.strengthen(dr, flipped.Flip(), c.id) {
+				break
+			}
+			if !s.claAllocator.Clauses[dr].IsRemoved() {
+				signatures[dr] = s.signature(d)
+				queue = append(queue, dr)
+			}
+		}
+	}
+
+	//The removed clauses are dropped from the lists and the promoted learnt clauses are moved to the problem clauses
+	s.clauses, s.learnts = s.clauses[:0], s.learnts[:0]
+	for _, cr := range refs {
+		c := s.claAllocator.Clauses[cr]
+		if c.IsRemoved() {
+			continue
+		}
+		if c.Learnt() {
+			s.learnts = append(s.learnts, cr)
+		} else {
+			s.clauses = append(s.clauses, cr)
+		}
+	}
+	return s.ok && s.simplify()
+}
+
+//signature returns the set of the variables of the clause hashed into 64 bits
+func (s *Solver) signature(c *Clause) uint64 {
+	var sig uint64
+	for i := 0; i < c.Size(); i++ {
+		q := c.At(i)
+		sig |= 1 << (uint(q.Var()) % 64)
+	}
+	return sig
+}
+
+//subsumes returns true if c subsumes d, or if c with one literal negated subsumes d
+//The negated literal of c is returned in the latter case, and a literal whose X is LitUndef in the former case
+//marks must be false for every literal and it is restored on return
+func subsumes(c, d *Clause, marks []bool) (bool, Lit) {
+	for i := 0; i < d.Size(); i++ {
+		marks[d.At(i).X] = true
+	}
+	flipped := Lit{X: LitUndef}
+	subsumed := true
+	for i := 0; i < c.Size() && subsumed; i++ {
+		q := c.At(i)
+		if marks[q.X] {
+			continue
+		}
+		if flipped.X == LitUndef && marks[q.Flip().X] {
+			flipped = q
+			continue
+		}
+		subsumed = false
+	}
+	for i := 0; i < d.Size(); i++ {
+		marks[d.At(i).X] = false
+	}
+	return subsumed, flipped
+}
+
+//strengthen removes p from the clause by resolution with the clause of the ID containing ~p
+//The literals false at level 0 are removed as well. A unit clause is propagated
+//It returns false if the problem is unsatisfiable
+func (s *Solver) strengthen(cr ClauseReference, p Lit, id uint64) bool {
+	c := s.claAllocator.GetClause(cr)
+	lits := make([]Lit, 0, c.Size()-1)
+	removed := []Lit{p}
+	var hints []uint64
+	for i := 0; i < c.Size(); i++ {
+		q := c.At(i)
+		if q == p {
+			continue
+		}
+		if s.valueLit(q) == LitBoolFalse {
+			hints = append(hints, s.unitID[q.Var()])
+			removed = append(removed, q)
+		} else {
+			lits = append(lits, q)
+		}
+	}
+	newID := s.newClauseID()
+	if s.proof != nil {
+		s.proof.add(newID, lits, append(hints, id, c.id))
+		s.proof.delete(c.id, c.Data[:c.Size()])
+	}
+	s.statistics.StrengthenedClauseCount++
+
+	if len(lits) <= 1 {
+		s.deleteClause(cr)
+		if len(lits) == 0 {
+			s.ok = false
+			return false
+		}
+		s.uncheckedEnqueue(lits[0], ClaRefUndef)
+		s.unitID[lits[0].Var()] = newID
+		if confl := s.propagate(); confl != ClaRefUndef {
+			s.ok = false
+			if s.proof != nil {
+				s.proofConflict(confl)
+			}
+			return false
+		}
+		return true
+	}
+	s.detachClause(cr)
+	//The removed literals are kept behind the size, so Restore can bring the clause back
+	copy(c.Data, lits)
+	copy(c.Data[len(lits):], removed)
+	for c.Size() > len(lits) {
+		c.Pop()
+	}
+	c.id = newID
+	if err := s.attachClause(cr); err != nil {
+		panic(err)
+	}
+	return true
+}
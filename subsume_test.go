@@ -0,0 +1,101 @@
+package gatosat
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/togatoga/gatosat/lrat"
+)
+
+func TestSubsume(t *testing.T) {
+	s := NewSolver()
+	for v := 0; v < 4; v++ {
+		s.NewVar()
+	}
+	a, b, c, d := *NewLit(0, false), *NewLit(1, false), *NewLit(2, false), *NewLit(3, false)
+	//(a | b) subsumes (a | b | c) and its duplicate, and strengthens (~a | b | d) into (b | d)
+	for _, lits := range [][]Lit{{a, b, c}, {a, b}, {b, a}, {a.Flip(), b, d}, {c, d}} {
+		s.AddClause(lits)
+	}
+	if !s.subsume() {
+		t.Fatal("The problem is unsatisfiable")
+	}
+	var clauses [][]Lit
+	for _, cr := range s.clauses {
+		cla := s.claAllocator.GetClause(cr)
+		clauses = append(clauses, cla.Data[:cla.Size()])
+	}
+	expected := [][]Lit{{a, b}, {b, d}, {c, d}}
+	if len(clauses) != len(expected) {
+		t.Fatalf("The clauses are wrong: %v (expected %v)", clauses, expected)
+	}
+	for i := range expected {
+		checked := map[Lit]bool{}
+		for _, q := range clauses[i] {
+			checked[q] = true
+		}
+		for _, q := range expected[i] {
+			if !checked[q] || len(clauses[i]) != len(expected[i]) {
+				t.Fatalf("The clauses are wrong: %v (expected %v)", clauses, expected)
+			}
+		}
+	}
+	if stats := s.Statistics(); stats.SubsumedClauseCount != 2 || stats.StrengthenedClauseCount != 1 {
+		t.Fatalf("The counters are wrong: %d subsumed %d strengthened", stats.SubsumedClauseCount, stats.StrengthenedClauseCount)
+	}
+}
+
+func TestSubsumeRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(3))
+	numVars := 10
+	var subsumed, strengthened uint64
+	for i := 0; i < 300; i++ {
+		//The random clauses with their supersets and the clauses differing in one sign
+		clauses := randomClauses(rnd, numVars, 10+rnd.Intn(20))
+		for _, c := range clauses[:len(clauses)/2] {
+			d := append([]Lit(nil), c...)
+			if rnd.Intn(2) == 0 {
+				d[0] = d[0].Flip()
+			}
+			clauses = append(clauses, append(d, *NewLit(Var(rnd.Intn(numVars)), rnd.Intn(2) == 0)))
+		}
+		opts := DefaultOptions()
+		opts.Subsumption = true
+		s, err := NewSolverWithOptions(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var proof bytes.Buffer
+		s.SetClauseStore(NewMemoryClauseStore())
+		s.SetProof(&proof, ProofLRAT)
+		for v := 0; v < numVars; v++ {
+			s.NewVar()
+		}
+		for _, c := range clauses {
+			s.AddClause(append([]Lit(nil), c...))
+		}
+		status := s.Solve()
+		if expected := bruteForce(numVars, clauses, nil); status != expected {
+			t.Fatalf("The solver returns a wrong value: %v (expected %v) clauses %v", status, expected, clauses)
+		}
+		if status == LitBoolTrue {
+			if err := s.VerifyModel(); err != nil {
+				t.Fatalf("The model is wrong: %v", err)
+			}
+		} else {
+			s.FlushProof()
+			if err := lrat.NewChecker(dimacsClauses(clauses)).Check(&proof); err != nil {
+				t.Fatalf("The LRAT proof is not verified: %v", err)
+			}
+		}
+		if err := s.CheckInvariants(); err != nil {
+			t.Fatal(err)
+		}
+		subsumed += s.Statistics().SubsumedClauseCount
+		strengthened += s.Statistics().StrengthenedClauseCount
+	}
+	if subsumed == 0 || strengthened == 0 {
+		t.Fatalf("No clause is subsumed or strengthened: %d subsumed %d strengthened", subsumed, strengthened)
+	}
+}
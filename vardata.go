@@ -1,4 +1,4 @@
-package main
+package gatosat
 
 type VarData struct {
 	Reason ClauseReference
@@ -12,10 +12,15 @@ func NewVarData(claRef ClauseReference, level int) *VarData {
 	}
 }
 
-func (s *Solver) Reason(x Var) ClauseReference {
-	return s.VarData[x].Reason
+//reason returns the reason clause of the variable
+//The reason of a literal propagated by the external propagator is asked when it is needed first
+func (s *Solver) reason(x Var) ClauseReference {
+	if s.varData[x].Reason == claRefLazy {
+		s.materializeReason(x)
+	}
+	return s.varData[x].Reason
 }
 
-func (s *Solver) Level(x Var) int {
-	return s.VarData[x].Level
+func (s *Solver) level(x Var) int {
+	return s.varData[x].Level
 }
@@ -1,4 +1,4 @@
-package main
+package gatosat
 
 //Watcher is the struct to detect conflicts
 type Watcher struct {
@@ -32,6 +32,19 @@ func NewWatches() *Watches {
 	return &Watches{}
 }
 
+//Clone returns a deep copy of the watches
+func (w *Watches) Clone() *Watches {
+	clone := &Watches{watches: make([][]*Watcher, len(w.watches))}
+	for i, ws := range w.watches {
+		clone.watches[i] = make([]*Watcher, len(ws))
+		for j, watcher := range ws {
+			copied := *watcher
+			clone.watches[i][j] = &copied
+		}
+	}
+	return clone
+}
+
 //Init append a new empty watcher if the size of watches is greater than a variable
 func (w *Watches) Init(v Var) {
 	size := 2*int(v) + 1